@@ -0,0 +1,389 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+var (
+	errDivideByZero = errors.New("cannot divide by zero")
+	errUndefined    = errors.New("result is not a real number")
+	errFactorial    = errors.New("factorial of negative or fractional number")
+)
+
+// Reports an operation whose exact result is not rational, such as the
+// square root of 2, when evaluating in rational mode without permission
+// to fall back to floating point.
+type InexactError struct {
+	Op string // Operator or function name.
+}
+
+func (e *InexactError) Error() string {
+	return fmt.Sprintf("%s has no exact rational result", e.Op)
+}
+
+// Most digits an exact result may have. Bounds the time and memory
+// spent on exact powers, which grow without limit.
+const maxDigits = 100_000
+
+// Reports an exact result too large to compute, such as "9^9^9" in
+// decimal mode.
+type TooLargeError struct {
+	Op string // Operator or function name.
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("%s result exceeds %d digits", e.Op, maxDigits)
+}
+
+// Reports whether an integer of "bits" bits, shifted by "zeros" decimal
+// places, raised to the natural number "n" has more than "maxDigits"
+// digits. Errs low on the bits, so that 2^n is exact.
+func exceeds(bits int, zeros, n int64) bool {
+	if n > 0 && zeros > maxDigits/n {
+		// Also guards the product below against overflow.
+		return true
+	}
+	return float64(bits-1)*math.Log10(2)*float64(n)+float64(zeros*n) > maxDigits
+}
+
+// Brings numbers "x" and "y" to a common type. Floats, being inexact,
+// absorb any other number. Rationals absorb decimals, both being exact.
+func promote(x, y Value) (Value, Value, error) {
+	if !isNumber(x) {
+		return nil, nil, fmt.Errorf("expected number, got %s", x)
+	}
+	if !isNumber(y) {
+		return nil, nil, fmt.Errorf("expected number, got %s", y)
+	}
+	switch {
+	case isKind[Number](x) || isKind[Number](y):
+		return Number(toFloat(x)), Number(toFloat(y)), nil
+	case isKind[Rational](x) || isKind[Rational](y):
+		return toRational(x), toRational(y), nil
+	default:
+		return x, y, nil
+	}
+}
+
+func isKind[T Value](v Value) bool {
+	_, ok := v.(T)
+	return ok
+}
+
+// Reports whether "v" is a number of any type.
+func isNumber(v Value) bool {
+	switch v.(type) {
+	case Number, Decimal, Rational:
+		return true
+	}
+	return false
+}
+
+// Converts any number into the float64 nearest it.
+func toFloat(v Value) float64 {
+	switch n := v.(type) {
+	case Number:
+		return float64(n)
+	case Decimal:
+		return n.Float64()
+	case Rational:
+		return n.Float64()
+	}
+	return math.NaN()
+}
+
+// Converts an exact number into a rational.
+func toRational(v Value) Rational {
+	switch n := v.(type) {
+	case Decimal:
+		return n.Rational()
+	case Rational:
+		return n
+	}
+	return Rational{}
+}
+
+// Outputs -1 if x < y, 0 if x == y, and +1 if x > y. Strings compare
+// in lexical order by byte.
+func compare(x, y Value) (int, error) {
+	if s, ok := x.(String); ok {
+		t, ok := y.(String)
+		if !ok {
+			return 0, fmt.Errorf("expected string, got %s", y)
+		}
+		return strings.Compare(string(s), string(t)), nil
+	}
+	a, b, err := promote(x, y)
+	if err != nil {
+		return 0, err
+	}
+	switch a := a.(type) {
+	case Number:
+		b := b.(Number)
+		switch {
+		case a < b:
+			return -1, nil
+		case a > b:
+			return 1, nil
+		}
+		return 0, nil
+	case Decimal:
+		return a.Cmp(b.(Decimal)), nil
+	default:
+		return a.(Rational).Cmp(b.(Rational)), nil
+	}
+}
+
+// Applies arithmetic operator "op" to "x" and "y". Strings only
+// concatenate.
+func (e *evaluator) arithmetic(op string, x, y Value) (Value, error) {
+	if isArray(x) || isArray(y) {
+		return e.arrayArithmetic(op, x, y)
+	}
+	if s, ok := x.(String); ok {
+		if t, ok := y.(String); ok && op == "+" {
+			return s + t, nil
+		}
+	}
+	a, b, err := promote(x, y)
+	if err != nil {
+		return nil, fmt.Errorf("operator %q not defined: %s", op, err)
+	}
+	switch a := a.(type) {
+	case Number:
+		return floatArithmetic(op, a, b.(Number))
+	case Decimal:
+		return e.decimalArithmetic(op, a, b.(Decimal))
+	default:
+		return e.rationalArithmetic(op, a.(Rational), b.(Rational))
+	}
+}
+
+func floatArithmetic(op string, a, b Number) (Value, error) {
+	switch op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, errDivideByZero
+		}
+		return a / b, nil
+	case "^":
+		return Number(math.Pow(float64(a), float64(b))), nil
+	default:
+		return nil, fmt.Errorf("undefined binary operator %q", op)
+	}
+}
+
+func (e *evaluator) decimalArithmetic(op string, a, b Decimal) (Value, error) {
+	switch op {
+	case "+":
+		return a.Add(b), nil
+	case "-":
+		return a.Sub(b), nil
+	case "*":
+		return a.Mul(b), nil
+	case "/":
+		return a.Quo(b, e.prec, e.Rounding)
+	case "^":
+		if n := b.Rat().Num(); b.IsInt() && n.IsInt64() {
+			return a.PowInt(n.Int64(), e.prec, e.Rounding)
+		}
+		// Irrational powers go through floating point, so they hold no
+		// more digits than a float, whatever the precision.
+		f := math.Pow(a.Float64(), b.Float64())
+		if math.IsNaN(f) {
+			return nil, errUndefined
+		}
+		return e.decimal(Number(f))
+	default:
+		return nil, fmt.Errorf("undefined binary operator %q", op)
+	}
+}
+
+func (e *evaluator) rationalArithmetic(op string, a, b Rational) (Value, error) {
+	switch op {
+	case "+":
+		return a.Add(b), nil
+	case "-":
+		return a.Sub(b), nil
+	case "*":
+		return a.Mul(b), nil
+	case "/":
+		return a.Quo(b)
+	case "^":
+		if n := b.ratio().Num(); b.IsInt() && n.IsInt64() {
+			return a.PowInt(n.Int64())
+		}
+		return e.inexact(op, math.Pow(a.Float64(), b.Float64()))
+	default:
+		return nil, fmt.Errorf("undefined binary operator %q", op)
+	}
+}
+
+// Converts float "n" into a decimal rounded to the evaluation's precision.
+func (e *evaluator) decimal(n Number) (Decimal, error) {
+	d, err := DecimalFromFloat(float64(n))
+	if err != nil {
+		return Decimal{}, err
+	}
+	return d.Round(e.prec, e.Rounding), nil
+}
+
+// Converts float "n", the result of an exact number, into a rational.
+// Reads the float as the shortest decimal that identifies it, so that
+// 0.1 becomes 1/10.
+func rational(n Number) (Rational, error) {
+	d, err := DecimalFromFloat(float64(n))
+	if err != nil {
+		return Rational{}, err
+	}
+	return d.Rational(), nil
+}
+
+// Handles float "f", the inexact result of "op" in rational mode.
+// Outputs "f" if the evaluator allows inexact results, otherwise
+// an *InexactError.
+func (e *evaluator) inexact(op string, f float64) (Value, error) {
+	if !e.AllowInexact {
+		return nil, &InexactError{Op: op}
+	}
+	if math.IsNaN(f) {
+		return nil, errUndefined
+	}
+	return Number(f), nil
+}
+
+// Outputs "x!", the product of the natural numbers through "x".
+// Exact numbers have exact factorials of up to "maxDigits" digits,
+// beyond which they fail with a *TooLargeError. Float factorials
+// beyond 170! overflow to infinity.
+func factorial(x Value) (Value, error) {
+	var n *big.Int
+	switch x := x.(type) {
+	case Number:
+		f := float64(x)
+		if f < 0 || f != math.Trunc(f) {
+			return nil, errFactorial
+		}
+		p := 1.0
+		for i := 2.0; i <= f && !math.IsInf(p, 1); i++ {
+			p *= i
+		}
+		return Number(p), nil
+	case Decimal:
+		if !x.IsInt() || x.Sign() < 0 {
+			return nil, errFactorial
+		}
+		n = x.Rat().Num()
+	case Rational:
+		if !x.IsInt() || x.Sign() < 0 {
+			return nil, errFactorial
+		}
+		n = x.ratio().Num()
+	}
+	// log10(n!) = ln Γ(n + 1) / ln 10
+	if lg, _ := math.Lgamma(float64(n.Int64()) + 1); !n.IsInt64() || lg/math.Ln10 > maxDigits {
+		return nil, &TooLargeError{Op: "!"}
+	}
+	p := new(big.Int).MulRange(1, n.Int64())
+	if _, ok := x.(Decimal); ok {
+		return Decimal{coef: p}, nil
+	}
+	return Rational{rat: new(big.Rat).SetInt(p)}, nil
+}
+
+// Outputs "-x", negating each element of a list or matrix.
+func negate(x Value) (Value, error) {
+	switch x := x.(type) {
+	case Number:
+		return -x, nil
+	case Decimal:
+		return x.Neg(), nil
+	case Rational:
+		return x.Neg(), nil
+	case List, Matrix:
+		return mapArray(x, negate)
+	}
+	return nil, fmt.Errorf("operator \"-\" not defined for %s", x)
+}
+
+// Applies relational operator "op" to "x" and "y". Equality is defined
+// for all values, ordering for numbers alone.
+func relate(op string, x, y Value) (Bool, error) {
+	switch op {
+	case "=":
+		return Bool(equal(x, y)), nil
+	case "≠":
+		return Bool(!equal(x, y)), nil
+	}
+	c, err := compare(x, y)
+	if err != nil {
+		return false, fmt.Errorf("operator %q not defined: %s", op, err)
+	}
+	switch op {
+	case "<":
+		return c < 0, nil
+	case ">":
+		return c > 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">=":
+		return c >= 0, nil
+	default:
+		return false, fmt.Errorf("undefined relational operator %q", op)
+	}
+}
+
+// Reports whether "x" and "y" are equal values. Numbers of different
+// types compare by value. Lists and matrices are equal if their
+// elements are.
+func equal(x, y Value) bool {
+	if isNumber(x) && isNumber(y) {
+		c, err := compare(x, y)
+		return err == nil && c == 0
+	}
+	switch a := x.(type) {
+	case String:
+		b, ok := y.(String)
+		return ok && a == b
+	case Bool:
+		b, ok := y.(Bool)
+		return ok && a == b
+	case List:
+		b, ok := y.(List)
+		return ok && equalValues(a, b)
+	case Matrix:
+		b, ok := y.(Matrix)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalValues(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func equalValues(xs, ys []Value) bool {
+	if len(xs) != len(ys) {
+		return false
+	}
+	for i := range xs {
+		if !equal(xs[i], ys[i]) {
+			return false
+		}
+	}
+	return true
+}
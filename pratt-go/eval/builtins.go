@@ -0,0 +1,330 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Built-in function. Inputs evaluated arguments, outputs either Value or
+// error. Errors are positioned at the call by the evaluator.
+type Builtin func(args ...Value) (Value, error)
+
+var registry = struct {
+	sync.RWMutex
+	fns map[string]Builtin
+}{fns: make(map[string]Builtin)}
+
+// Registers a built-in function under "name", replacing any function
+// of the same name. Safe for concurrent use.
+func Register(name string, fn Builtin) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.fns[name] = fn
+}
+
+// Outputs the built-in function registered under "name".
+func Lookup(name string) (Builtin, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	fn, ok := registry.fns[name]
+	return fn, ok
+}
+
+// Helper functions convert arguments to floats. Exact numbers lose precision.
+
+func floats(args []Value) ([]float64, error) {
+	xs := make([]float64, len(args))
+	for i, arg := range args {
+		if !isNumber(arg) {
+			return nil, fmt.Errorf("argument %d: expected number, got %s", i+1, arg)
+		}
+		xs[i] = toFloat(arg)
+	}
+	return xs, nil
+}
+
+// Converts arguments to strings, which must number "n".
+func texts(args []Value, n int) ([]string, error) {
+	if len(args) != n {
+		return nil, fmt.Errorf("expected %d arguments, got %d", n, len(args))
+	}
+	ss := make([]string, n)
+	for i, arg := range args {
+		s, ok := arg.(String)
+		if !ok {
+			return nil, fmt.Errorf("argument %d: expected string, got %s", i+1, arg)
+		}
+		ss[i] = string(s)
+	}
+	return ss, nil
+}
+
+// Lifts a float function of one argument into a Builtin.
+func unary(f func(float64) (float64, error)) Builtin {
+	return func(args ...Value) (Value, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		xs, err := floats(args)
+		if err != nil {
+			return nil, err
+		}
+		x, err := f(xs[0])
+		if err != nil {
+			return nil, err
+		}
+		return Number(x), nil
+	}
+}
+
+// Lifts a total float function into one that never fails.
+func total(f func(float64) float64) func(float64) (float64, error) {
+	return func(x float64) (float64, error) { return f(x), nil }
+}
+
+// Helper functions preserve exact numbers. Each inputs one or more
+// numbers of any type and outputs a number of their common type.
+
+// Lifts a function of one number into a Builtin.
+func exact(f func(Value) (Value, error)) Builtin {
+	return func(args ...Value) (Value, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		if !isNumber(args[0]) {
+			return nil, fmt.Errorf("argument 1: expected number, got %s", args[0])
+		}
+		return f(args[0])
+	}
+}
+
+// Lifts a fold over one or more numbers into a Builtin.
+func fold(f func(acc, x Value) (Value, error)) Builtin {
+	return func(args ...Value) (Value, error) {
+		if len(args) == 0 {
+			return nil, errors.New("expected at least 1 argument, got 0")
+		}
+		if _, err := floats(args); err != nil {
+			return nil, err
+		}
+		acc := args[0]
+		for _, x := range args[1:] {
+			v, err := f(acc, x)
+			if err != nil {
+				return nil, err
+			}
+			acc = v
+		}
+		return acc, nil
+	}
+}
+
+// Lifts a function of one number into its numerical derivative,
+// approximated by a central difference. Outputs a float.
+func derivative(fn Builtin) Builtin {
+	return unary(func(x float64) (float64, error) {
+		h := math.Cbrt(epsilon) * math.Max(1, math.Abs(x))
+		hi, err := fn(Number(x + h))
+		if err != nil {
+			return 0, err
+		}
+		lo, err := fn(Number(x - h))
+		if err != nil {
+			return 0, err
+		}
+		if !isNumber(hi) || !isNumber(lo) {
+			return 0, errors.New("derivative of non-numerical function")
+		}
+		return (toFloat(hi) - toFloat(lo)) / (2 * h), nil
+	})
+}
+
+// Distance from 1 to the next float64.
+const epsilon = 0x1p-52
+
+// Outputs x + y, exactly unless either is a float.
+func add(x, y Value) (Value, error) {
+	a, b, err := promote(x, y)
+	if err != nil {
+		return nil, err
+	}
+	switch a := a.(type) {
+	case Number:
+		return a + b.(Number), nil
+	case Decimal:
+		return a.Add(b.(Decimal)), nil
+	default:
+		return a.(Rational).Add(b.(Rational)), nil
+	}
+}
+
+// Outputs "x" rounded to an integer by "mode".
+func integral(x Value, mode big.RoundingMode) Value {
+	switch x := x.(type) {
+	case Decimal:
+		r := x.Rat()
+		return Decimal{coef: roundQuo(r.Num(), r.Denom(), mode)}
+	case Rational:
+		r := x.ratio()
+		return Rational{rat: new(big.Rat).SetInt(roundQuo(r.Num(), r.Denom(), mode))}
+	}
+	f := float64(x.(Number))
+	switch mode {
+	case big.ToNegativeInf:
+		return Number(math.Floor(f))
+	case big.ToPositiveInf:
+		return Number(math.Ceil(f))
+	default:
+		return Number(math.Round(f))
+	}
+}
+
+func init() {
+	Register("sin", unary(total(math.Sin)))
+	Register("cos", unary(total(math.Cos)))
+	Register("tan", unary(total(math.Tan)))
+	Register("asin", unary(total(math.Asin)))
+	Register("acos", unary(total(math.Acos)))
+	Register("atan", unary(total(math.Atan)))
+	Register("exp", unary(total(math.Exp)))
+	Register("abs", exact(func(x Value) (Value, error) {
+		switch n := x.(type) {
+		case Decimal:
+			if n.Sign() < 0 {
+				return n.Neg(), nil
+			}
+		case Rational:
+			if n.Sign() < 0 {
+				return n.Neg(), nil
+			}
+		case Number:
+			return Number(math.Abs(float64(n))), nil
+		}
+		return x, nil
+	}))
+	Register("floor", exact(func(x Value) (Value, error) {
+		return integral(x, big.ToNegativeInf), nil
+	}))
+	Register("ceil", exact(func(x Value) (Value, error) {
+		return integral(x, big.ToPositiveInf), nil
+	}))
+	// Rounds half away from zero.
+	Register("round", exact(func(x Value) (Value, error) {
+		return integral(x, big.ToNearestAway), nil
+	}))
+	// Exact for rationals whose numerator and denominator are perfect squares.
+	Register("sqrt", exact(func(x Value) (Value, error) {
+		if r, ok := x.(Rational); ok {
+			if s, ok := r.Sqrt(); ok {
+				return s, nil
+			}
+		}
+		f := toFloat(x)
+		if f < 0 {
+			return nil, errors.New("square root of negative number")
+		}
+		return Number(math.Sqrt(f)), nil
+	}))
+	Register("ln", unary(func(x float64) (float64, error) {
+		if x <= 0 {
+			return 0, errors.New("logarithm of non-positive number")
+		}
+		return math.Log(x), nil
+	}))
+	// Common logarithm. Given a second argument, logarithm to that base.
+	Register("log", func(args ...Value) (Value, error) {
+		if len(args) != 1 && len(args) != 2 {
+			return nil, fmt.Errorf("expected 1 or 2 arguments, got %d", len(args))
+		}
+		xs, err := floats(args)
+		if err != nil {
+			return nil, err
+		}
+		if xs[0] <= 0 {
+			return nil, errors.New("logarithm of non-positive number")
+		}
+		if len(xs) == 1 {
+			return Number(math.Log10(xs[0])), nil
+		}
+		if xs[1] <= 0 || xs[1] == 1 {
+			return nil, errors.New("logarithm base must be positive and not 1")
+		}
+		return Number(math.Log(xs[0]) / math.Log(xs[1])), nil
+	})
+	Register("min", fold(func(acc, x Value) (Value, error) {
+		c, err := compare(x, acc)
+		if err != nil || c >= 0 {
+			return acc, err
+		}
+		return x, nil
+	}))
+	Register("max", fold(func(acc, x Value) (Value, error) {
+		c, err := compare(x, acc)
+		if err != nil || c <= 0 {
+			return acc, err
+		}
+		return x, nil
+	}))
+	Register("sum", fold(add))
+	// Counts the characters of a string or the elements of a list.
+	// Outputs an exact integer.
+	Register("len", func(args ...Value) (Value, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+		}
+		var n int
+		switch x := args[0].(type) {
+		case String:
+			n = utf8.RuneCountInString(string(x))
+		case List:
+			n = len(x)
+		default:
+			return nil, fmt.Errorf("argument 1: expected string or list, got %s", x)
+		}
+		return Decimal{coef: big.NewInt(int64(n))}, nil
+	})
+	Register("upper", func(args ...Value) (Value, error) {
+		ss, err := texts(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return String(strings.ToUpper(ss[0])), nil
+	})
+	Register("lower", func(args ...Value) (Value, error) {
+		ss, err := texts(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return String(strings.ToLower(ss[0])), nil
+	})
+	// Reports whether the first string contains the second.
+	Register("contains", func(args ...Value) (Value, error) {
+		ss, err := texts(args, 2)
+		if err != nil {
+			return nil, err
+		}
+		return Bool(strings.Contains(ss[0], ss[1])), nil
+	})
+	// Divides exactly. The evaluator rounds decimal averages by its
+	// precision and rounding mode.
+	Register("avg", func(args ...Value) (Value, error) {
+		s, err := fold(add)(args...)
+		if err != nil {
+			return nil, err
+		}
+		n := int64(len(args))
+		switch s := s.(type) {
+		case Decimal:
+			return s.Rational().Quo(Rational{rat: big.NewRat(n, 1)})
+		case Rational:
+			return s.Quo(Rational{rat: big.NewRat(n, 1)})
+		default:
+			return s.(Number) / Number(n), nil
+		}
+	})
+}
@@ -0,0 +1,303 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an arbitrary-precision decimal number: coefficient × 10^exponent.
+// Addition, subtraction, multiplication, and integer powers are exact.
+// Division and irrational operations round to a given number of significant
+// digits. Decimals are immutable. The zero value is 0.
+type Decimal struct {
+	coef *big.Int // Tracks significant digits. Nil means zero.
+	exp  int      // Tracks the decimal point.
+}
+
+// Commonly-used big integers. Never modified.
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+// Inputs a decimal string — digits with an optional decimal point and
+// an optional exponent, such as "-12.5e3" — and outputs a Decimal.
+func ParseDecimal(s string) (Decimal, error) {
+	text := s
+	exp := 0
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		e, err := strconv.Atoi(text[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+		}
+		exp = e
+		text = text[:i]
+	}
+	if i := strings.IndexByte(text, '.'); i >= 0 {
+		exp -= len(text) - i - 1
+		text = text[:i] + text[i+1:]
+	}
+	coef, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+	return Decimal{coef: coef, exp: exp}.normalize(), nil
+}
+
+// Outputs the decimal nearest "f" with no more digits than needed to
+// identify it. Infinities and NaN have no decimal form.
+func DecimalFromFloat(f float64) (Decimal, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return Decimal{}, fmt.Errorf("%g has no decimal form", f)
+	}
+	return ParseDecimal(strconv.FormatFloat(f, 'e', -1, 64))
+}
+
+func (d Decimal) coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// Removes trailing zeros from the coefficient.
+func (d Decimal) normalize() Decimal {
+	c := d.coefficient()
+	if c.Sign() == 0 {
+		return Decimal{}
+	}
+	if c.Bit(0) == 1 {
+		// Odd, so no trailing zeros.
+		return d
+	}
+	// Counts the zeros in one conversion, rather than dividing by ten
+	// once for each.
+	s := c.Text(10)
+	k := len(s) - len(strings.TrimRight(s, "0"))
+	if k == 0 {
+		return d
+	}
+	return Decimal{coef: new(big.Int).Quo(c, pow10(k)), exp: d.exp + k}
+}
+
+// Outputs the sign of "d": -1, 0, or +1.
+func (d Decimal) Sign() int {
+	return d.coefficient().Sign()
+}
+
+// Outputs the decimal in plain notation, without trailing zeros.
+func (d Decimal) String() string {
+	d = d.normalize()
+	c := d.coefficient()
+	digits := new(big.Int).Abs(c).String()
+	sign := ""
+	if c.Sign() < 0 {
+		sign = "-"
+	}
+	switch {
+	case d.exp >= 0:
+		return sign + digits + strings.Repeat("0", d.exp)
+	case -d.exp < len(digits):
+		i := len(digits) + d.exp
+		return sign + digits[:i] + "." + digits[i:]
+	default:
+		return sign + "0." + strings.Repeat("0", -d.exp-len(digits)) + digits
+	}
+}
+
+// Outputs the float64 nearest "d".
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.coefficient().String()+"e"+strconv.Itoa(d.exp), 64)
+	return f
+}
+
+// Outputs "d" as an exact rational.
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.coefficient())
+	p := new(big.Rat).SetInt(pow10(abs(d.exp)))
+	if d.exp >= 0 {
+		return r.Mul(r, p)
+	}
+	return r.Quo(r, p)
+}
+
+// Reports whether "d" is a whole number.
+func (d Decimal) IsInt() bool {
+	return d.normalize().exp >= 0
+}
+
+// Outputs -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.coefficient()), exp: d.exp}
+}
+
+// Outputs -1 if d < e, 0 if d == e, and +1 if d > e.
+func (d Decimal) Cmp(e Decimal) int {
+	x, y := align(d, e)
+	return x.Cmp(y)
+}
+
+// Outputs d + e. Exact.
+func (d Decimal) Add(e Decimal) Decimal {
+	x, y := align(d, e)
+	return Decimal{coef: x.Add(x, y), exp: min(d.exp, e.exp)}
+}
+
+// Outputs d - e. Exact.
+func (d Decimal) Sub(e Decimal) Decimal {
+	x, y := align(d, e)
+	return Decimal{coef: x.Sub(x, y), exp: min(d.exp, e.exp)}
+}
+
+// Outputs d × e. Exact.
+func (d Decimal) Mul(e Decimal) Decimal {
+	c := new(big.Int).Mul(d.coefficient(), e.coefficient())
+	return Decimal{coef: c, exp: d.exp + e.exp}
+}
+
+// Outputs d ÷ e rounded to "prec" significant digits by "mode".
+// Division by zero is undefined.
+func (d Decimal) Quo(e Decimal, prec int, mode big.RoundingMode) (Decimal, error) {
+	if e.Sign() == 0 {
+		return Decimal{}, errDivideByZero
+	}
+	if d.Sign() == 0 {
+		return Decimal{}, nil
+	}
+	x, y := d.coefficient(), e.coefficient()
+	// Scale the dividend so that the quotient carries at least one digit
+	// beyond the precision, then fold any remainder into a final sticky
+	// digit. Rounding then sees exact ties only where they truly exist.
+	k := prec + 1 + digits(y) - digits(x)
+	if k < 0 {
+		k = 0
+	}
+	n := new(big.Int).Mul(x, pow10(k))
+	q, r := new(big.Int).QuoRem(n, y, new(big.Int))
+	exp := d.exp - e.exp - k
+	if r.Sign() != 0 {
+		q.Mul(q, bigTen)
+		if n.Sign()*y.Sign() < 0 {
+			q.Sub(q, bigOne)
+		} else {
+			q.Add(q, bigOne)
+		}
+		exp -= 1
+	}
+	return Decimal{coef: q, exp: exp}.Round(prec, mode), nil
+}
+
+// Outputs d^n for integer "n". Exact for non-negative "n". Negative
+// powers divide, rounding to "prec" significant digits by "mode".
+// Fails with a *TooLargeError rather than compute a power of more
+// than "maxDigits" digits.
+func (d Decimal) PowInt(n int64, prec int, mode big.RoundingMode) (Decimal, error) {
+	if n < 0 {
+		if d.Sign() == 0 {
+			return Decimal{}, errDivideByZero
+		}
+		p, err := d.PowInt(-n, prec, mode)
+		if err != nil {
+			return Decimal{}, err
+		}
+		return Decimal{coef: big.NewInt(1)}.Quo(p, prec, mode)
+	}
+	x := d.normalize()
+	if x.exceeds(n) {
+		return Decimal{}, &TooLargeError{Op: "^"}
+	}
+	// Exponentiation by squaring.
+	z := Decimal{coef: big.NewInt(1)}
+	for n > 0 {
+		if n&1 == 1 {
+			z = z.Mul(x)
+		}
+		x = x.Mul(x)
+		n >>= 1
+	}
+	return z, nil
+}
+
+// Reports whether d^n, for natural number "n", has more than "maxDigits"
+// digits, counting the zeros that its exponent places before or after
+// the coefficient.
+func (d Decimal) exceeds(n int64) bool {
+	d = d.normalize()
+	return exceeds(d.coefficient().BitLen(), int64(abs(d.exp)), n)
+}
+
+// Outputs "d" rounded to "prec" significant digits by "mode".
+func (d Decimal) Round(prec int, mode big.RoundingMode) Decimal {
+	c := d.coefficient()
+	drop := digits(c) - prec
+	if drop <= 0 {
+		return d
+	}
+	return Decimal{coef: roundQuo(c, pow10(drop), mode), exp: d.exp + drop}
+}
+
+// Outputs n ÷ m rounded to an integer by "mode". "m" is positive.
+func roundQuo(n, m *big.Int, mode big.RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(n, m, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	sign := int64(n.Sign())
+	away := false
+	// Compares the remainder to half of "m".
+	r.Abs(r)
+	half := r.Add(r, r).Cmp(m)
+	switch mode {
+	case big.ToZero:
+	case big.AwayFromZero:
+		away = true
+	case big.ToNegativeInf:
+		away = sign < 0
+	case big.ToPositiveInf:
+		away = sign > 0
+	case big.ToNearestAway:
+		away = half >= 0
+	default: // big.ToNearestEven
+		away = half > 0 || (half == 0 && q.Bit(0) == 1)
+	}
+	if away {
+		q.Add(q, big.NewInt(sign))
+	}
+	return q
+}
+
+// Outputs the coefficients of "d" and "e" scaled to a common exponent.
+func align(d, e Decimal) (*big.Int, *big.Int) {
+	x := new(big.Int).Set(d.coefficient())
+	y := new(big.Int).Set(e.coefficient())
+	switch {
+	case d.exp > e.exp:
+		x.Mul(x, pow10(d.exp-e.exp))
+	case e.exp > d.exp:
+		y.Mul(y, pow10(e.exp-d.exp))
+	}
+	return x, y
+}
+
+// Outputs 10^n for non-negative "n".
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// Counts the decimal digits of "n", ignoring its sign.
+func digits(n *big.Int) int {
+	if n.Sign() == 0 {
+		return 1
+	}
+	return len(new(big.Int).Abs(n).String())
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,142 @@
+package eval
+
+import (
+	"errors"
+	"github/jared-richard-clarke/pratt/parser"
+	"math/big"
+	"testing"
+)
+
+func TestDecimalEval(t *testing.T) {
+	tests := []struct {
+		text      string
+		precision int
+		rounding  big.RoundingMode
+		expect    string
+	}{
+		{"0.1 + 0.2", 0, big.ToNearestEven, "0.3"},
+		{"0.3 - 0.1", 0, big.ToNearestEven, "0.2"},
+		{"1.005 × 100", 0, big.ToNearestEven, "100.5"},
+		{"10^20 + 1", 0, big.ToNearestEven, "100000000000000000001"},
+		{"21!", 0, big.ToNearestEven, "51090942171709440000"},
+		{"0.1%", 0, big.ToNearestEven, "0.001"},
+		{"2^-2", 0, big.ToNearestEven, "0.25"},
+		{"1 ÷ 3", 5, big.ToNearestEven, "0.33333"},
+		{"2 ÷ 3", 3, big.ToNearestEven, "0.667"},
+		{"2 ÷ 3", 3, big.ToZero, "0.666"},
+		{"(-2) ÷ 3", 3, big.ToNegativeInf, "-0.667"},
+		{"(-2) ÷ 3", 3, big.ToPositiveInf, "-0.666"},
+		{"5 ÷ 2", 1, big.ToNearestEven, "2"},
+		{"5 ÷ 2", 1, big.ToNearestAway, "3"},
+		{"7 ÷ 2", 1, big.ToNearestEven, "4"},
+		{"1 ÷ 8", 2, big.AwayFromZero, "0.13"},
+		{"sqrt(2)", 10, big.ToNearestEven, "1.414213562"},
+		{"1 ÷ 7", 40, big.ToNearestEven, "0.1428571428571428571428571428571428571429"},
+		// Computed in floating point, so no more digits than a float holds.
+		{"sqrt(2)", 40, big.ToNearestEven, "1.4142135623730951"},
+		{"2^0.5", 40, big.ToNearestEven, "1.4142135623730951"},
+		{"1.5x", 0, big.ToNearestEven, "0.15"},
+	}
+	env := Env{"x": Number(0.1)}
+	for _, test := range tests {
+		node, err := parser.Parse(test.text)
+		if err != nil {
+			t.Fatalf("TestDecimalEval failed for %q. Got: %s", test.text, err)
+		}
+		ev := Evaluator{
+			Mode:      DecimalMode,
+			Precision: test.precision,
+			Rounding:  test.rounding,
+		}
+		result, err := ev.Eval(node, env)
+		if err != nil {
+			t.Errorf("TestDecimalEval failed for %q. Expected: %s, Got: %s", test.text, test.expect, err)
+			continue
+		}
+		if result.String() != test.expect {
+			t.Errorf("TestDecimalEval failed for %q. Expected: %s, Got: %s", test.text, test.expect, result)
+		}
+	}
+}
+
+func TestDecimalEqual(t *testing.T) {
+	node, _ := parser.Parse("0.1 + 0.2 = 0.3")
+	ev := Evaluator{Mode: DecimalMode}
+	result, err := ev.Eval(node, nil)
+	if err != nil || result != Bool(true) {
+		t.Errorf("TestDecimalEqual failed. Expected: true, Got: %v %v", result, err)
+	}
+	result, err = Eval(node, nil)
+	if err != nil || result != Bool(false) {
+		t.Errorf("TestDecimalEqual (float) failed. Expected: false, Got: %v %v", result, err)
+	}
+}
+
+func TestDecimalDivideByZero(t *testing.T) {
+	node, _ := parser.Parse("1 ÷ (0.5 - 0.5)")
+	ev := Evaluator{Mode: DecimalMode}
+	if result, err := ev.Eval(node, nil); err == nil {
+		t.Errorf("TestDecimalDivideByZero failed. Expected: error, Got: %s", result)
+	}
+}
+
+func TestDecimalTooLarge(t *testing.T) {
+	ev := Evaluator{Mode: DecimalMode}
+	for _, text := range []string{"9^9^9", "0.9^-(9^9)", "10^100000000", "0.1^100000000", "10^4611686018427387904 < 1"} {
+		node, _ := parser.Parse(text)
+		result, err := ev.Eval(node, nil)
+		var large *TooLargeError
+		if !errors.As(err, &large) {
+			t.Errorf("TestDecimalTooLarge failed for %q. Expected: *TooLargeError, Got: %v %v", text, result, err)
+		}
+	}
+	node, _ := parser.Parse("1^(9^9) + (0.5 - 0.5)^(9^9) + 2^1000 - 2^1000")
+	if result, err := ev.Eval(node, nil); err != nil || result.String() != "1" {
+		t.Errorf("TestDecimalTooLarge failed. Expected: 1, Got: %v %v", result, err)
+	}
+}
+
+func TestDecimalAverage(t *testing.T) {
+	tests := []struct {
+		precision int
+		rounding  big.RoundingMode
+		expect    string
+	}{
+		{0, big.ToNearestEven, "1.666666666666666666666666666666667"},
+		{5, big.ToNearestEven, "1.6667"},
+		{5, big.ToZero, "1.6666"},
+	}
+	node, _ := parser.Parse("avg(1, 2, 2)")
+	for _, test := range tests {
+		ev := Evaluator{Mode: DecimalMode, Precision: test.precision, Rounding: test.rounding}
+		result, err := ev.Eval(node, nil)
+		if err != nil || result.String() != test.expect {
+			t.Errorf("TestDecimalAverage failed for precision %d. Expected: %s, Got: %v %v", test.precision, test.expect, result, err)
+		}
+	}
+	result, err := Eval(node, nil)
+	if err != nil || result != Number(5.0/3) {
+		t.Errorf("TestDecimalAverage (float) failed. Expected: %v, Got: %v %v", 5.0/3, result, err)
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		text, expect string
+	}{
+		{"0", "0"},
+		{"007.50", "7.5"},
+		{"-12.5e3", "-12500"},
+		{"1e-5", "0.00001"},
+		{"123.456", "123.456"},
+	}
+	for _, test := range tests {
+		d, err := ParseDecimal(test.text)
+		if err != nil || d.String() != test.expect {
+			t.Errorf("TestParseDecimal failed for %q. Expected: %s, Got: %v %v", test.text, test.expect, d, err)
+		}
+	}
+	if d, err := ParseDecimal("1.2.3"); err == nil {
+		t.Errorf("TestParseDecimal failed. Expected: error, Got: %s", d)
+	}
+}
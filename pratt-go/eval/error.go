@@ -0,0 +1,71 @@
+package eval
+
+import (
+	"fmt"
+	"github/jared-richard-clarke/pratt/parser"
+)
+
+// Describes an evaluation error and the position of the node that raised it.
+type Error struct {
+	Line, Column int
+	Msg          string // Description of error, without position.
+	Err          error  // Underlying error, if any. Usually raised by a built-in function.
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s line:%d column:%d", e.Msg, e.Line, e.Column)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Outputs an error positioned at node "n".
+func errorf(n parser.Node, format string, args ...any) *Error {
+	line, column := position(n)
+	return &Error{
+		Line:   line,
+		Column: column,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+// Positions error "err" at node "n".
+func wrap(n parser.Node, err error) *Error {
+	line, column := position(n)
+	return &Error{
+		Line:   line,
+		Column: column,
+		Msg:    err.Error(),
+		Err:    err,
+	}
+}
+
+// Outputs the line and column of node "n". Operations are positioned
+// at their operators, other nodes where they begin.
+func position(n parser.Node) (int, int) {
+	switch n := n.(type) {
+	case nil:
+		return 0, 0
+	case parser.Postfix:
+		return n.Line, n.Column
+	case parser.Binary:
+		return n.Line, n.Column
+	case parser.ImpliedBinary:
+		return n.Line, n.Column
+	case parser.Comparison:
+		return n.Line, n.Column
+	case parser.Conditional:
+		return n.Line, n.Column
+	case parser.Assign:
+		return n.Line, n.Column
+	case parser.Lambda:
+		return n.Line, n.Column
+	case parser.FuncDef:
+		return n.Line, n.Column
+	case parser.Call:
+		return n.Line, n.Column
+	case parser.Index:
+		return n.Line, n.Column
+	}
+	pos := n.Pos()
+	return pos.Line, pos.Column
+}
@@ -0,0 +1,548 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"github/jared-richard-clarke/pratt/parser"
+	"maps"
+	"math"
+	"math/big"
+)
+
+// Binds symbols to values.
+type Env map[string]Value
+
+// Symbols bound in every environment, unless shadowed.
+var constants = map[string]Value{
+	"pi": Number(math.Pi),
+	"π":  Number(math.Pi),
+	"e":  Number(math.E),
+}
+
+// Selects the kind of number that evaluation computes with.
+type Mode int
+
+const (
+	FloatMode    Mode = iota // 64-bit floating point. Numbers evaluate to Number.
+	DecimalMode              // Arbitrary-precision decimal. Numbers evaluate to Decimal.
+	RationalMode             // Exact fraction. Numbers evaluate to Rational.
+)
+
+// Default significant digits for inexact decimal results.
+const DefaultPrecision = 34
+
+// Maximum depth of nested calls of user-defined functions. Stops
+// runaway recursion.
+const maxDepth = 1000
+
+// Configures evaluation. The zero value evaluates with 64-bit floats.
+type Evaluator struct {
+	Mode Mode
+	// Significant digits of inexact decimal results. Zero selects
+	// "DefaultPrecision". Division and negative powers honor any
+	// precision. Irrational powers, such as "2^0.5", and built-in
+	// functions, such as "sqrt(2)", compute in floating point, so their
+	// results hold at most 17 digits, the last of which may be off.
+	Precision int
+	Rounding  big.RoundingMode // Rounding of inexact decimal results. The zero value rounds half to even.
+	// In rational mode, operations without an exact rational result, such
+	// as "2^0.5", "sqrt(2)", or "pi", fall back to floating point if true.
+	// Otherwise they fail with an *InexactError.
+	AllowInexact bool
+}
+
+type evaluator struct {
+	env   Env
+	vars  Env // Bindings made by the assignments of a program, or the parameters of a function.
+	prec  int
+	depth int // Calls of user-defined functions in progress.
+	*Evaluator
+}
+
+// Evaluator API: inputs AST and environment, outputs either Value or Error.
+// Strings concatenate by "+" and compare in lexical order.
+// Symbols resolve first to the assignments of a program, in order, then
+// to "env", then to the constants "pi" and "e". A program outputs the
+// value of its last statement.
+// Calls resolve to user-defined functions, then to built-in functions.
+// Lambdas and function definitions evaluate to closures, which capture
+// the bindings in scope where they are defined. The environment is only
+// read, never written, so it may be shared by concurrent evaluations.
+func Eval(node parser.Node, env Env) (Value, error) {
+	return new(Evaluator).Eval(node, env)
+}
+
+// Like "Eval" but computes with the number type selected by "ev.Mode".
+// In decimal mode, "0.1 + 0.2" is exactly "0.3". In rational mode,
+// "1/3 + 1/6" is exactly "1/2". Float values found in the environment
+// become numbers of the selected type.
+func (ev *Evaluator) Eval(node parser.Node, env Env) (Value, error) {
+	e := evaluator{
+		env:       env,
+		prec:      ev.Precision,
+		Evaluator: ev,
+	}
+	if e.prec <= 0 {
+		e.prec = DefaultPrecision
+	}
+	return e.eval(node)
+}
+
+func (e *evaluator) eval(node parser.Node) (Value, error) {
+	switch n := node.(type) {
+	case parser.Number:
+		return e.number(n)
+	case parser.Symbol:
+		return e.lookup(n)
+	case parser.String:
+		return String(n.Value), nil
+	case parser.Boolean:
+		return Bool(n.Value), nil
+	case parser.Unary:
+		return e.unary(n)
+	case parser.Postfix:
+		return e.postfix(n)
+	case parser.Binary:
+		if n.Op == "and" || n.Op == "or" {
+			return e.logical(n)
+		}
+		return e.binary(n, n.Op, n.X, n.Y)
+	case parser.ImpliedBinary:
+		return e.binary(n, n.Op, n.X, n.Y)
+	case parser.Comparison:
+		return e.comparison(n)
+	case parser.Conditional:
+		return e.conditional(n)
+	case parser.Call:
+		return e.call(n)
+	case parser.List:
+		return e.list(n)
+	case parser.Matrix:
+		return e.matrix(n)
+	case parser.Index:
+		return e.index(n)
+	case parser.Lambda:
+		return e.closure(n.Params, n.Body), nil
+	case parser.FuncDef:
+		return e.define(n), nil
+	case parser.Assign:
+		return e.assign(n)
+	case parser.Program:
+		return e.program(n)
+	case parser.Paren:
+		return e.eval(n.X)
+	case parser.Empty:
+		return nil, errorf(n, "empty expression")
+	case parser.Bad:
+		return nil, errorf(n, "cannot evaluate malformed expression")
+	default:
+		return nil, errorf(n, "unknown node %v", n)
+	}
+}
+
+func (e *evaluator) lookup(s parser.Symbol) (Value, error) {
+	if v, ok := e.vars[s.Value]; ok {
+		return v, nil
+	}
+	if v, ok := e.env[s.Value]; ok {
+		n, ok := v.(Number)
+		if !ok {
+			return v, nil
+		}
+		v, err := e.convert(n)
+		if err != nil {
+			return nil, wrap(s, err)
+		}
+		return v, nil
+	}
+	if v, ok := constants[s.Value]; ok {
+		n := v.(Number)
+		if e.Mode == RationalMode {
+			// Irrational.
+			v, err := e.inexact(s.Value, float64(n))
+			if err != nil {
+				return nil, wrap(s, err)
+			}
+			return v, nil
+		}
+		v, err := e.convert(n)
+		if err != nil {
+			return nil, wrap(s, err)
+		}
+		return v, nil
+	}
+	return nil, errorf(s, "undefined symbol %q", s.Value)
+}
+
+// Converts float "n" into the number type of the evaluation mode.
+func (e *evaluator) convert(n Number) (Value, error) {
+	switch e.Mode {
+	case DecimalMode:
+		return e.decimal(n)
+	case RationalMode:
+		return rational(n)
+	default:
+		return n, nil
+	}
+}
+
+// Converts a number literal into the number type of the evaluation mode.
+func (e *evaluator) number(n parser.Number) (Value, error) {
+	if e.Mode == FloatMode || n.Raw == "" {
+		// Nodes built by hand, not by the parser, may lack literal text.
+		v, err := e.convert(Number(n.Value))
+		if err != nil {
+			return nil, wrap(n, err)
+		}
+		return v, nil
+	}
+	d, err := ParseDecimal(n.Raw)
+	if err != nil {
+		return nil, wrap(n, err)
+	}
+	if e.Mode == RationalMode {
+		return d.Rational(), nil
+	}
+	return d, nil
+}
+
+func (e *evaluator) unary(u parser.Unary) (Value, error) {
+	x, err := e.eval(u.X)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Op {
+	case "+", "-":
+	case "not":
+		b, ok := x.(Bool)
+		if !ok {
+			return nil, errorf(u, "operator %q not defined for %s", u.Op, x)
+		}
+		return !b, nil
+	default:
+		return nil, errorf(u, "undefined unary operator %q", u.Op)
+	}
+	if !isNumber(x) && !isArray(x) {
+		return nil, errorf(u, "operator %q not defined for %s", u.Op, x)
+	}
+	if u.Op == "+" {
+		return x, nil
+	}
+	v, err := negate(x)
+	if err != nil {
+		return nil, wrap(u, err)
+	}
+	return v, nil
+}
+
+// Evaluates factorials and percentages. A derivative, such as "f'",
+// evaluates only when called.
+func (e *evaluator) postfix(p parser.Postfix) (Value, error) {
+	if p.Op == "'" {
+		return nil, errorf(p, "derivative %s must be called", parser.Print(p))
+	}
+	x, err := e.eval(p.X)
+	if err != nil {
+		return nil, err
+	}
+	if !isNumber(x) {
+		return nil, errorf(p, "operator %q not defined for %s", p.Op, x)
+	}
+	var v Value
+	switch p.Op {
+	case "!":
+		v, err = factorial(x)
+	case "%":
+		var hundred Value
+		if hundred, err = e.convert(Number(100)); err == nil {
+			v, err = e.arithmetic("/", x, hundred)
+		}
+	default:
+		return nil, errorf(p, "undefined postfix operator %q", p.Op)
+	}
+	if err != nil {
+		return nil, wrap(p, err)
+	}
+	return v, nil
+}
+
+// Evaluates both Binary and ImpliedBinary nodes, "node" locating errors.
+func (e *evaluator) binary(node parser.Node, op string, l, r parser.Node) (Value, error) {
+	x, err := e.eval(l)
+	if err != nil {
+		return nil, err
+	}
+	y, err := e.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "=", "≠", "<", ">", "<=", ">=":
+		b, err := relate(op, x, y)
+		if err != nil {
+			return nil, wrap(node, err)
+		}
+		return b, nil
+	}
+	v, err := e.arithmetic(op, x, y)
+	if err != nil {
+		return nil, wrap(node, err)
+	}
+	return v, nil
+}
+
+// Evaluates "and" and "or", which short-circuit: the right operand is
+// evaluated only if the left does not decide the result. Both operands
+// must be booleans.
+func (e *evaluator) logical(b parser.Binary) (Value, error) {
+	x, err := e.eval(b.X)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := x.(Bool)
+	if !ok {
+		return nil, errorf(b, "operator %q not defined for %s", b.Op, x)
+	}
+	if (b.Op == "and") != bool(p) {
+		// "false and y" is false, "true or y" is true.
+		return p, nil
+	}
+	y, err := e.eval(b.Y)
+	if err != nil {
+		return nil, err
+	}
+	q, ok := y.(Bool)
+	if !ok {
+		return nil, errorf(b, "operator %q not defined for %s", b.Op, y)
+	}
+	return q, nil
+}
+
+// Evaluates a chain of comparisons, such as "0 ≤ x < 10", as the
+// conjunction of its links: "0 ≤ x ∧ x < 10". Evaluates each operand
+// at most once, left to right, stopping at the first false link.
+func (e *evaluator) comparison(c parser.Comparison) (Value, error) {
+	if len(c.Operands) != len(c.Ops)+1 {
+		return nil, errorf(c, "comparison of %d operators has %d operands", len(c.Ops), len(c.Operands))
+	}
+	x, err := e.eval(c.Operands[0])
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range c.Ops {
+		y, err := e.eval(c.Operands[i+1])
+		if err != nil {
+			return nil, err
+		}
+		b, err := relate(op, x, y)
+		if err != nil {
+			return nil, wrap(c, err)
+		}
+		if !b {
+			return b, nil
+		}
+		x = y
+	}
+	return Bool(true), nil
+}
+
+// Evaluates each statement in turn, outputting the value of the last.
+func (e *evaluator) program(p parser.Program) (Value, error) {
+	if len(p.Stmts) == 0 {
+		return nil, errorf(p, "empty program")
+	}
+	var v Value
+	for _, stmt := range p.Stmts {
+		var err error
+		if v, err = e.eval(stmt); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Binds a name for the statements that follow, shadowing any earlier
+// binding. Outputs the bound value.
+func (e *evaluator) assign(a parser.Assign) (Value, error) {
+	v, err := e.eval(a.Value)
+	if err != nil {
+		return nil, err
+	}
+	e.bind(a.Name.Value, v)
+	return v, nil
+}
+
+// Binds a function for the statements that follow. Within its body,
+// its name refers to itself, so that it may recur. Outputs the function.
+func (e *evaluator) define(d parser.FuncDef) Value {
+	fn := e.closure(d.Params, d.Body)
+	fn.vars[d.Name.Value] = fn
+	e.bind(d.Name.Value, fn)
+	return fn
+}
+
+func (e *evaluator) bind(name string, v Value) {
+	if e.vars == nil {
+		e.vars = make(Env)
+	}
+	e.vars[name] = v
+}
+
+// Outputs a function of "params" that evaluates "body" with a copy of
+// the bindings now in scope. Later assignments do not affect it.
+func (e *evaluator) closure(params []parser.Symbol, body parser.Node) *Closure {
+	c := &Closure{
+		Params: make([]string, len(params)),
+		Body:   body,
+		vars:   maps.Clone(e.vars),
+	}
+	if c.vars == nil {
+		c.vars = make(Env)
+	}
+	for i, p := range params {
+		c.Params[i] = p.Value
+	}
+	return c
+}
+
+// Lifts closure "c" into a Builtin that evaluates its body, with its
+// parameters bound to the arguments, by the settings of "e".
+func (e *evaluator) apply(c *Closure) Builtin {
+	return func(args ...Value) (Value, error) {
+		if len(args) != len(c.Params) {
+			return nil, fmt.Errorf("expected %d arguments, got %d", len(c.Params), len(args))
+		}
+		if e.depth >= maxDepth {
+			return nil, errors.New("maximum call depth exceeded")
+		}
+		inner := *e
+		inner.vars = maps.Clone(c.vars)
+		for i, p := range c.Params {
+			inner.vars[p] = args[i]
+		}
+		inner.depth += 1
+		return inner.eval(c.Body)
+	}
+}
+
+// Evaluates the condition, then only the branch it selects.
+func (e *evaluator) conditional(c parser.Conditional) (Value, error) {
+	x, err := e.eval(c.Cond)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := x.(Bool)
+	if !ok {
+		return nil, errorf(c, "condition must be a boolean, got %s", x)
+	}
+	if b {
+		return e.eval(c.Then)
+	}
+	return e.eval(c.Else)
+}
+
+func (e *evaluator) call(c parser.Call) (Value, error) {
+	fn, err := e.function(c.Callee)
+	if err != nil {
+		return nil, err
+	}
+	name := parser.Print(c.Callee)
+	args := make([]Value, len(c.Args))
+	for i, arg := range c.Args {
+		v, err := e.eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := fn(args...)
+	if _, ok := err.(*Error); ok {
+		// Raised, and positioned, within the body of a user-defined function.
+		return nil, err
+	}
+	if err != nil {
+		line, column := position(c)
+		return nil, &Error{
+			Line:   line,
+			Column: column,
+			Msg:    name + ": " + err.Error(),
+			Err:    err,
+		}
+	}
+	// Float results of built-in functions take the number type of the
+	// evaluation mode. In rational mode they are inexact. Exact results,
+	// such as lengths, convert exactly, except that rationals round to
+	// the precision of decimal mode.
+	switch n := v.(type) {
+	case Number:
+		if e.Mode == FloatMode {
+			break
+		}
+		if e.Mode == RationalMode {
+			v, err = e.inexact(name, float64(n))
+		} else {
+			v, err = e.decimal(n)
+		}
+		if err != nil {
+			return nil, wrap(c, err)
+		}
+	case Decimal:
+		switch e.Mode {
+		case FloatMode:
+			v = Number(n.Float64())
+		case RationalMode:
+			v = n.Rational()
+		}
+	case Rational:
+		switch e.Mode {
+		case FloatMode:
+			v = Number(n.Float64())
+		case DecimalMode:
+			r := n.ratio()
+			v, err = Decimal{coef: r.Num()}.Quo(Decimal{coef: r.Denom()}, e.prec, e.Rounding)
+			if err != nil {
+				return nil, wrap(c, err)
+			}
+		}
+	}
+	return v, nil
+}
+
+// Resolves callee "n" to a function. A symbol resolves to a function
+// it is bound to, if any, or else to a built-in function. A callee
+// followed by primes, such as "f'", resolves to a numerical derivative.
+// Any other callee must evaluate to a closure.
+func (e *evaluator) function(n parser.Node) (Builtin, error) {
+	switch n := n.(type) {
+	case parser.Symbol:
+		if c, ok := e.vars[n.Value].(*Closure); ok {
+			return e.apply(c), nil
+		}
+		if c, ok := e.env[n.Value].(*Closure); ok {
+			return e.apply(c), nil
+		}
+		fn, ok := Lookup(n.Value)
+		if !ok {
+			return nil, errorf(n, "undefined function %q", n.Value)
+		}
+		return fn, nil
+	case parser.Postfix:
+		if n.Op != "'" {
+			break
+		}
+		fn, err := e.function(n.X)
+		if err != nil {
+			return nil, err
+		}
+		return derivative(fn), nil
+	}
+	v, err := e.eval(n)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := v.(*Closure)
+	if !ok {
+		return nil, errorf(n, "%s is not a function", v)
+	}
+	return e.apply(c), nil
+}
@@ -0,0 +1,316 @@
+package eval
+
+import (
+	"errors"
+	"github/jared-richard-clarke/pratt/parser"
+	"math"
+	"testing"
+)
+
+func run(text string, env Env) (Value, error) {
+	node, err := parser.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return Eval(node, env)
+}
+
+func TestEval(t *testing.T) {
+	env := Env{"x": Number(3), "y": Number(4)}
+	tests := []struct {
+		text   string
+		expect Value
+	}{
+		{"1 + 2 * 3", Number(7)},
+		{"(1 + 2) * 3", Number(9)},
+		{"2 ^ 3 ^ 2", Number(512)},
+		{"-2 ^ 2", Number(-4)},
+		{"7 ÷ 2", Number(3.5)},
+		{"2x + 1", Number(7)},
+		{"(x + 1)(y - 1)", Number(12)},
+		{"2(x)", Number(6)},
+		{"1 + 2 = 3", Bool(true)},
+		{"x ≠ y", Bool(true)},
+		{"0 ≤ x < 10", Bool(true)},
+		{"x < y < 2", Bool(false)},
+		{"x >= 3", Bool(true)},
+		{"2x > y", Bool(true)},
+		{"1 > 2 < wyvern", Bool(false)},
+		{"true and not false", Bool(true)},
+		{"x > 1 ∧ y < 4 ∨ x = 3", Bool(true)},
+		{"x = 3 && !(y = 4)", Bool(false)},
+		{"false and wyvern", Bool(false)},
+		{"true || wyvern", Bool(true)},
+		{"sqrt(x^2 + y^2)", Number(5)},
+		{"max(x, 11, y)", Number(11)},
+		{"min(x, y) + sum(1, 2, 3)", Number(9)},
+		{"log(1000)", Number(3)},
+		{"log(8, 2)", Number(3)},
+		{"cos(0)", Number(1)},
+		{"2pi", Number(2 * math.Pi)},
+		{"2^3!", Number(64)},
+		{"3!x", Number(18)},
+		{"0! + 1!", Number(2)},
+		{"50%", Number(0.5)},
+		{"x%y", Number(0.12)},
+		{"x > 0 ? x : -x", Number(3)},
+		{"if x < 0 then 1 else if x = 3 then 2 else 3", Number(2)},
+		{"x > y ? 1 : x = y ? 2 : 3", Number(3)},
+		{"true ? 1 : wyvern", Number(1)},
+		{"1 + (false ? 1 / 0 : 2)", Number(3)},
+	}
+	for _, test := range tests {
+		result, err := run(test.text, env)
+		if err != nil {
+			t.Errorf("TestEval failed for %q. Expected: %s, Got: %s", test.text, test.expect, err)
+			continue
+		}
+		if result != test.expect {
+			t.Errorf("TestEval failed for %q. Expected: %s, Got: %s", test.text, test.expect, result)
+		}
+	}
+}
+
+func TestDerivative(t *testing.T) {
+	tests := []struct {
+		text   string
+		expect float64
+	}{
+		{"sin'(0)", 1},
+		{"exp'(1)", math.E},
+		{"sqrt'(4)", 0.25},
+		{"cos''(0)", -1},
+		{"ln′(2)", 0.5},
+	}
+	for _, test := range tests {
+		result, err := run(test.text, nil)
+		if err != nil {
+			t.Errorf("TestDerivative failed for %q. Expected: %g, Got: %s", test.text, test.expect, err)
+			continue
+		}
+		if n, ok := result.(Number); !ok || math.Abs(float64(n)-test.expect) > 1e-6 {
+			t.Errorf("TestDerivative failed for %q. Expected: %g, Got: %s", test.text, test.expect, result)
+		}
+	}
+}
+
+func TestProgram(t *testing.T) {
+	tests := []struct {
+		text   string
+		expect Value
+	}{
+		{"r = 3; area = r^2; area", Number(9)},
+		{"let x = 2\nx = x + 1\nx * y", Number(12)},
+		{"pi = 3; pi", Number(3)},
+		{"a = (1 = 1); a", Bool(true)},
+		{"x = 10", Number(10)},
+	}
+	for _, test := range tests {
+		node, err := parser.ParseProgram(test.text)
+		if err != nil {
+			t.Fatalf("TestProgram failed for %q. Got: %s", test.text, err)
+		}
+		env := Env{"x": Number(1), "y": Number(4)}
+		result, err := Eval(node, env)
+		if err != nil || result != test.expect {
+			t.Errorf("TestProgram failed for %q. Expected: %s, Got: %v %v", test.text, test.expect, result, err)
+		}
+		// The environment is never written.
+		if env["x"] != Number(1) {
+			t.Errorf("TestProgram failed for %q. Environment modified: %v", test.text, env)
+		}
+	}
+	node, _ := parser.ParseProgram("a = 1\nb = a + c")
+	_, err := Eval(node, nil)
+	var e *Error
+	if !errors.As(err, &e) || e.Line != 2 || e.Column != 9 {
+		t.Errorf("TestProgram failed. Expected: error at line:2 column:9, Got: %v", err)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []struct {
+		text   string
+		expect Value
+	}{
+		{"f(x, y) := x^2 + y; f(3, 1)", Number(10)},
+		{"(x -> 2x)(3)", Number(6)},
+		{"(() -> 7)()", Number(7)},
+		{"twice(f, x) := f(f(x)); twice(x ↦ x + 3, 1)", Number(7)},
+		{"fact(n) := if n = 0 then 1 else n fact(n - 1); fact(5)", Number(120)},
+		{"adder(a) := x -> x + a; add2 = adder(2); add2(5)", Number(7)},
+		{"a = 1; f(x) := x + a; a = 10; f(0)", Number(1)},
+		{"sin = x -> 2x; sin(1)", Number(2)},
+		{"x = 5; f(x) := x; f(1) + x", Number(6)},
+		{"k(a) := b -> a - b; k(5)(2)", Number(3)},
+		{"add(a) := b -> c -> a + b + c; add(1)(2)(3)", Number(6)},
+		{"k(a) := (b, c) -> a + b c; k(1)(2, 3)", Number(7)},
+		{"g = 2; (g)(3)", Number(6)},
+	}
+	for _, test := range tests {
+		node, err := parser.ParseProgram(test.text)
+		if err != nil {
+			t.Fatalf("TestFunctions failed for %q. Got: %s", test.text, err)
+		}
+		result, err := Eval(node, nil)
+		if err != nil || result != test.expect {
+			t.Errorf("TestFunctions failed for %q. Expected: %s, Got: %v %v", test.text, test.expect, result, err)
+		}
+	}
+	node, _ := parser.ParseProgram("f(x) := x^3; f'(2)")
+	result, err := Eval(node, nil)
+	if n, ok := result.(Number); err != nil || !ok || math.Abs(float64(n)-12) > 1e-6 {
+		t.Errorf("TestFunctions failed. Expected: 12, Got: %v %v", result, err)
+	}
+	result, err = run("x -> x + 1", nil)
+	if err != nil || result.String() != "(x) -> x + 1" {
+		t.Errorf("TestFunctions failed. Expected: (x) -> x + 1, Got: %v %v", result, err)
+	}
+	errs := []struct {
+		text         string
+		line, column int
+	}{
+		{"f(x) := x; f(1, 2)", 1, 13},
+		{"f(x) := y; f(1)", 1, 9},
+		{"f(n) := f(n); f(1)", 1, 10},
+		{"(2!)'(1)", 1, 1},
+		{"g = 2; g(1)", 1, 8},
+		{"k(a) := b -> a / b; k(1)(0)", 1, 16},
+		{"k(a) := (b, c) -> a; k(1)(2)", 1, 26},
+	}
+	for _, test := range errs {
+		node, _ := parser.ParseProgram(test.text)
+		result, err := Eval(node, nil)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("TestFunctions failed for %q. Expected: *Error, Got: %v %v", test.text, result, err)
+			continue
+		}
+		if e.Line != test.line || e.Column != test.column {
+			t.Errorf("TestFunctions failed for %q. Expected: line:%d column:%d, Got: %s", test.text, test.line, test.column, e)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	env := Env{"name": String("Ada")}
+	tests := []struct {
+		text   string
+		expect Value
+	}{
+		{`"Ada"`, String("Ada")},
+		{`'it\'s' + " " + "ok\n"`, String("it's ok\n")},
+		{`name = "Ada"`, Bool(true)},
+		{`name = 'Ada' and name ≠ "ada"`, Bool(true)},
+		{`"Ada" < "Bob" <= "Bob"`, Bool(true)},
+		{`"a" = 1`, Bool(false)},
+		{`len("héllo")`, Number(5)},
+		{`len(["a", "b"]) + 1`, Number(3)},
+		{`upper(name)`, String("ADA")},
+		{`lower("ÉTÉ")`, String("été")},
+		{`contains(name + " Lovelace", "Love")`, Bool(true)},
+		{`contains("", "a") ? 1 : 2`, Number(2)},
+	}
+	for _, test := range tests {
+		result, err := run(test.text, env)
+		if err != nil || result != test.expect {
+			t.Errorf("TestStrings failed for %q. Expected: %s, Got: %v %v", test.text, test.expect, result, err)
+		}
+	}
+	result, err := run(`["a", "b"] + "!"`, nil)
+	if err != nil || result.String() != `["a!", "b!"]` {
+		t.Errorf("TestStrings failed. Expected: [\"a!\", \"b!\"], Got: %v %v", result, err)
+	}
+	ev := Evaluator{Mode: RationalMode}
+	node, _ := parser.Parse(`len("abc") / 2`)
+	result, err = ev.Eval(node, nil)
+	if err != nil || result.String() != "3/2" {
+		t.Errorf("TestStrings failed. Expected: 3/2, Got: %v %v", result, err)
+	}
+	errs := []struct {
+		text         string
+		line, column int
+	}{
+		{`"a" - "b"`, 1, 5},
+		{`"a" + 1`, 1, 5},
+		{`"a" < 1`, 1, 5},
+		{`-"a"`, 1, 1},
+		{`len(1)`, 1, 4},
+		{`upper("a", "b")`, 1, 6},
+		{`contains("a", 1)`, 1, 9},
+		{`"a"(1)`, 1, 1},
+		{"1 +\n 'a'(1)", 2, 2},
+	}
+	for _, test := range errs {
+		result, err := run(test.text, nil)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("TestStrings failed for %q. Expected: *Error, Got: %v %v", test.text, result, err)
+			continue
+		}
+		if e.Line != test.line || e.Column != test.column {
+			t.Errorf("TestStrings failed for %q. Expected: line:%d column:%d, Got: %s", test.text, test.line, test.column, e)
+		}
+	}
+}
+
+func TestEnvShadowsConstants(t *testing.T) {
+	result, err := run("e + 1", Env{"e": Number(1)})
+	if err != nil || result != Number(2) {
+		t.Errorf("TestEnvShadowsConstants failed. Expected: 2, Got: %v %v", result, err)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		text         string
+		line, column int
+	}{
+		{"1 + 1 / 0", 1, 7},
+		{"2 * wyvern", 1, 5},
+		{"1 +\n  nope(2)", 2, 3},
+		{"sqrt(1, 2)", 1, 5},
+		{"sqrt(-1)", 1, 5},
+		{"(1 = 1) + 2", 1, 9},
+		{"(1 = 1) < 2", 1, 9},
+		{"1 < 2 < wyvern", 1, 9},
+		{"true and wyvern", 1, 10},
+		{"1 or true", 1, 3},
+		{"true and 1", 1, 6},
+		{"not 1", 1, 1},
+		{"-true", 1, 1},
+		{"3x", 1, 2},
+		{"(-1)!", 1, 5},
+		{"2.5!", 1, 4},
+		{"true%", 1, 5},
+		{"sqrt'", 1, 5},
+		{"nope'(1)", 1, 1},
+		{"1 ? 2 : 3", 1, 3},
+		{"if 2 then 1 else 2", 1, 1},
+	}
+	for _, test := range tests {
+		result, err := run(test.text, nil)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("TestEvalErrors failed for %q. Expected: *Error, Got: %v %v", test.text, result, err)
+			continue
+		}
+		if e.Line != test.line || e.Column != test.column {
+			t.Errorf("TestEvalErrors failed for %q. Expected: line:%d column:%d, Got: %s", test.text, test.line, test.column, e)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("double", func(args ...Value) (Value, error) {
+		xs, err := floats(args)
+		if err != nil {
+			return nil, err
+		}
+		return Number(2 * xs[0]), nil
+	})
+	result, err := run("double(21)", nil)
+	if err != nil || result != Number(42) {
+		t.Errorf("TestRegister failed. Expected: 42, Got: %v %v", result, err)
+	}
+}
@@ -0,0 +1,392 @@
+package eval
+
+import (
+	"fmt"
+	"github/jared-richard-clarke/pratt/parser"
+	"math"
+	"strings"
+)
+
+// List of values, as in "[1, 2, 3]". Lists may hold values of any
+// type, including other lists.
+type List []Value
+
+func (l List) String() string {
+	return "[" + join(l) + "]"
+}
+
+// Matrix of numbers, as in "[1 2; 3 4]". Every row has the same
+// number of columns, and there is at least one row.
+type Matrix [][]Value
+
+func (m Matrix) String() string {
+	rows := make([]string, len(m))
+	for i, row := range m {
+		rows[i] = join(row)
+	}
+	if len(rows) == 1 {
+		// A lone row, unlike a list, ends in a semicolon.
+		return "[" + rows[0] + ";]"
+	}
+	return "[" + strings.Join(rows, "; ") + "]"
+}
+
+func (l List) value()   {}
+func (m Matrix) value() {}
+
+func join(vs []Value) string {
+	s := make([]string, len(vs))
+	for i, v := range vs {
+		s[i] = v.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+// Outputs the dimensions of "m", as in "2×3".
+func (m Matrix) shape() string {
+	return fmt.Sprintf("%d×%d", len(m), len(m[0]))
+}
+
+func (e *evaluator) list(l parser.List) (Value, error) {
+	vs := make(List, len(l.Elems))
+	for i, x := range l.Elems {
+		v, err := e.eval(x)
+		if err != nil {
+			return nil, err
+		}
+		vs[i] = v
+	}
+	return vs, nil
+}
+
+// Evaluates a matrix literal. Shape errors are positioned at the first
+// element of the offending row.
+func (e *evaluator) matrix(m parser.Matrix) (Value, error) {
+	if len(m.Rows) == 0 || len(m.Rows[0]) == 0 {
+		return nil, errorf(m, "empty matrix")
+	}
+	cols := len(m.Rows[0])
+	vs := make(Matrix, len(m.Rows))
+	for i, row := range m.Rows {
+		if len(row) != cols {
+			var at parser.Node = m
+			if len(row) > 0 {
+				at = row[0]
+			}
+			return nil, errorf(at, "row %d has %d columns, expected %d", i+1, len(row), cols)
+		}
+		vs[i] = make([]Value, cols)
+		for j, x := range row {
+			v, err := e.eval(x)
+			if err != nil {
+				return nil, err
+			}
+			if !isNumber(v) {
+				return nil, errorf(x, "matrix element must be a number, got %s", v)
+			}
+			vs[i][j] = v
+		}
+	}
+	return vs, nil
+}
+
+// Evaluates an index into a list or matrix. Indices count from 1. A
+// list takes one index. A matrix takes a row and, optionally, a column.
+// A nil index, ":", selects every row or column.
+func (e *evaluator) index(n parser.Index) (Value, error) {
+	x, err := e.eval(n.X)
+	if err != nil {
+		return nil, err
+	}
+	switch x := x.(type) {
+	case List:
+		if len(n.Indices) != 1 {
+			return nil, errorf(n, "list takes 1 index, got %d", len(n.Indices))
+		}
+		i, err := e.subscript(n.Indices[0], len(x))
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 {
+			return x, nil
+		}
+		return x[i], nil
+	case Matrix:
+		if len(n.Indices) < 1 || len(n.Indices) > 2 {
+			return nil, errorf(n, "matrix takes 1 or 2 indices, got %d", len(n.Indices))
+		}
+		i, err := e.subscript(n.Indices[0], len(x))
+		if err != nil {
+			return nil, err
+		}
+		j := -1
+		if len(n.Indices) == 2 {
+			if j, err = e.subscript(n.Indices[1], len(x[0])); err != nil {
+				return nil, err
+			}
+		}
+		switch {
+		case i < 0 && j < 0:
+			return x, nil
+		case i < 0:
+			col := make(List, len(x))
+			for k, row := range x {
+				col[k] = row[j]
+			}
+			return col, nil
+		case j < 0:
+			return List(x[i]), nil
+		default:
+			return x[i][j], nil
+		}
+	default:
+		return nil, errorf(n, "cannot index %s", x)
+	}
+}
+
+// Evaluates index "n" into a dimension of "size" elements. Outputs
+// the zero-based index, or -1 if "n" is nil and so selects them all.
+func (e *evaluator) subscript(n parser.Node, size int) (int, error) {
+	if n == nil {
+		return -1, nil
+	}
+	v, err := e.eval(n)
+	if err != nil {
+		return 0, err
+	}
+	if !isNumber(v) {
+		return 0, errorf(n, "index must be a number, got %s", v)
+	}
+	f := toFloat(v)
+	if f != math.Trunc(f) {
+		return 0, errorf(n, "index must be an integer, got %s", v)
+	}
+	if f < 1 || f > float64(size) {
+		return 0, errorf(n, "index %s out of range [1, %d]", v, size)
+	}
+	return int(f) - 1, nil
+}
+
+// Reports whether "v" is a list or matrix.
+func isArray(v Value) bool {
+	switch v.(type) {
+	case List, Matrix:
+		return true
+	}
+	return false
+}
+
+// Applies function "f" to each element of list or matrix "v".
+func mapArray(v Value, f func(Value) (Value, error)) (Value, error) {
+	switch v := v.(type) {
+	case List:
+		vs := make(List, len(v))
+		for i, x := range v {
+			y, err := f(x)
+			if err != nil {
+				return nil, err
+			}
+			vs[i] = y
+		}
+		return vs, nil
+	case Matrix:
+		vs := make(Matrix, len(v))
+		for i, row := range v {
+			r, err := mapArray(List(row), f)
+			if err != nil {
+				return nil, err
+			}
+			vs[i] = r.(List)
+		}
+		return vs, nil
+	}
+	return f(v)
+}
+
+// Applies arithmetic operator "op" to "x" and "y", at least one of
+// which is a list or matrix. Lists combine element by element, and
+// a scalar combines with every element of a list or matrix. Matrices
+// add and subtract element by element but multiply as matrices: a list
+// multiplies a matrix as a row vector on the left, or as a column
+// vector on the right. A square matrix raised to a natural number is
+// its repeated product.
+func (e *evaluator) arrayArithmetic(op string, x, y Value) (Value, error) {
+	switch a := x.(type) {
+	case List:
+		switch b := y.(type) {
+		case List:
+			if len(a) != len(b) {
+				return nil, fmt.Errorf("operator %q not defined for lists of lengths %d and %d", op, len(a), len(b))
+			}
+			vs := make(List, len(a))
+			for i := range a {
+				v, err := e.arithmetic(op, a[i], b[i])
+				if err != nil {
+					return nil, err
+				}
+				vs[i] = v
+			}
+			return vs, nil
+		case Matrix:
+			if op != "*" {
+				break
+			}
+			row, err := e.product(Matrix{a}, b)
+			if err != nil {
+				return nil, err
+			}
+			return List(row[0]), nil
+		default:
+			return mapArray(a, func(v Value) (Value, error) {
+				return e.arithmetic(op, v, b)
+			})
+		}
+	case Matrix:
+		switch b := y.(type) {
+		case Matrix:
+			switch op {
+			case "+", "-":
+				if len(a) != len(b) || len(a[0]) != len(b[0]) {
+					return nil, fmt.Errorf("operator %q not defined for %s and %s matrices", op, a.shape(), b.shape())
+				}
+				vs := make(Matrix, len(a))
+				for i := range a {
+					v, err := e.arithmetic(op, List(a[i]), List(b[i]))
+					if err != nil {
+						return nil, err
+					}
+					vs[i] = v.(List)
+				}
+				return vs, nil
+			case "*":
+				return e.product(a, b)
+			}
+		case List:
+			if op != "*" {
+				break
+			}
+			col := make(Matrix, len(b))
+			for i, v := range b {
+				col[i] = []Value{v}
+			}
+			m, err := e.product(a, col)
+			if err != nil {
+				return nil, err
+			}
+			vs := make(List, len(m))
+			for i, row := range m {
+				vs[i] = row[0]
+			}
+			return vs, nil
+		default:
+			if op == "^" {
+				return e.power(a, b)
+			}
+			return mapArray(a, func(v Value) (Value, error) {
+				return e.arithmetic(op, v, b)
+			})
+		}
+	default:
+		return mapArray(y, func(v Value) (Value, error) {
+			return e.arithmetic(op, a, v)
+		})
+	}
+	return nil, fmt.Errorf("operator %q not defined for %s and %s", op, x, y)
+}
+
+// Outputs the matrix product of "a" and "b".
+func (e *evaluator) product(a, b Matrix) (Matrix, error) {
+	if len(a[0]) != len(b) {
+		return nil, fmt.Errorf("cannot multiply %s matrix by %s matrix", a.shape(), b.shape())
+	}
+	zero, err := e.convert(Number(0))
+	if err != nil {
+		return nil, err
+	}
+	vs := make(Matrix, len(a))
+	for i := range a {
+		vs[i] = make([]Value, len(b[0]))
+		for j := range b[0] {
+			sum := zero
+			for k := range b {
+				p, err := e.arithmetic("*", a[i][k], b[k][j])
+				if err != nil {
+					return nil, err
+				}
+				if sum, err = e.arithmetic("+", sum, p); err != nil {
+					return nil, err
+				}
+			}
+			vs[i][j] = sum
+		}
+	}
+	return vs, nil
+}
+
+// Outputs "m" raised to natural number "n" by repeated squaring. Exact
+// powers fail with a *TooLargeError once an element exceeds "maxDigits"
+// digits.
+func (e *evaluator) power(m Matrix, n Value) (Value, error) {
+	if len(m) != len(m[0]) {
+		return nil, fmt.Errorf("cannot raise %s matrix to a power: not square", m.shape())
+	}
+	f := toFloat(n)
+	if !isNumber(n) || f < 0 || f != math.Trunc(f) {
+		return nil, fmt.Errorf("matrix power must be a natural number, got %s", n)
+	}
+	if f >= math.MaxInt64 {
+		return nil, fmt.Errorf("matrix power %s too large", n)
+	}
+	zero, err := e.convert(Number(0))
+	if err != nil {
+		return nil, err
+	}
+	one, err := e.convert(Number(1))
+	if err != nil {
+		return nil, err
+	}
+	p := make(Matrix, len(m))
+	for i := range p {
+		p[i] = make([]Value, len(m))
+		for j := range p[i] {
+			p[i][j] = zero
+		}
+		p[i][i] = one
+	}
+	for k := int64(f); k > 0; k /= 2 {
+		if k%2 == 1 {
+			if p, err = e.product(p, m); err != nil {
+				return nil, err
+			}
+		}
+		if k > 1 {
+			if m, err = e.product(m, m); err != nil {
+				return nil, err
+			}
+			if huge(m) {
+				return nil, &TooLargeError{Op: "^"}
+			}
+		}
+	}
+	return p, nil
+}
+
+// Reports whether any exact element of "m" has more than "maxDigits"
+// digits.
+func huge(m Matrix) bool {
+	for _, row := range m {
+		for _, v := range row {
+			switch v := v.(type) {
+			case Decimal:
+				if v.exceeds(1) {
+					return true
+				}
+			case Rational:
+				if v.exceeds(1) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
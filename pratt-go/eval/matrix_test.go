@@ -0,0 +1,143 @@
+package eval
+
+import (
+	"errors"
+	"github/jared-richard-clarke/pratt/parser"
+	"testing"
+)
+
+func TestMatrix(t *testing.T) {
+	env := Env{"v": List{Number(1), Number(2), Number(3)}}
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{"[1, 2, 3]", "[1, 2, 3]"},
+		{"[[1, 2], [3, 4]]", "[[1, 2], [3, 4]]"},
+		{"[1 2; 3 4]", "[1, 2; 3, 4]"},
+		{"[1 2;]", "[1, 2;]"},
+		{"[]", "[]"},
+		{"[1 + 1, true, x -> x]", "[2, true, (x) -> x]"},
+		{"v[2]", "2"},
+		{"v[:]", "[1, 2, 3]"},
+		{"[[1, 2], [3, 4]][2][1]", "3"},
+		{"[1 2; 3 4][2, 1]", "3"},
+		{"[1 2; 3 4][1, :]", "[1, 2]"},
+		{"[1 2; 3 4][:, 2]", "[2, 4]"},
+		{"[1 2; 3 4][2]", "[3, 4]"},
+		{"v + [10, 20, 30]", "[11, 22, 33]"},
+		{"v * v", "[1, 4, 9]"},
+		{"2v", "[2, 4, 6]"},
+		{"v ^ 2", "[1, 4, 9]"},
+		{"1 / [2, 4]", "[0.5, 0.25]"},
+		{"-v", "[-1, -2, -3]"},
+		{"[[1], [2]] + 1", "[[2], [3]]"},
+		{"[1 2; 3 4] + [10 20; 30 40]", "[11, 22; 33, 44]"},
+		{"[1 2; 3 4] - 1", "[0, 1; 2, 3]"},
+		{"[1 2; 3 4] * [5 6; 7 8]", "[19, 22; 43, 50]"},
+		{"[1 2 3;] * [1; 2; 3]", "[14;]"},
+		{"[1 2; 3 4] * [1, 1]", "[3, 7]"},
+		{"[1, 1] * [1 2; 3 4]", "[4, 6]"},
+		{"[1 1; 1 0] ^ 10", "[89, 55; 55, 34]"},
+		{"[1 2; 3 4] ^ 0", "[1, 0; 0, 1]"},
+		{"[1, 2] = [1, 2]", "true"},
+		{"[1 2;] = [1, 2]", "false"},
+		{"[1 2; 3 4] ≠ [1 2; 3 5]", "true"},
+		{"f(x) := x[1]; f([7, 8])", "7"},
+	}
+	for _, test := range tests {
+		node, err := parser.ParseProgram(test.text)
+		if err != nil {
+			t.Fatalf("TestMatrix failed for %q. Got: %s", test.text, err)
+		}
+		result, err := Eval(node, env)
+		if err != nil || result.String() != test.expect {
+			t.Errorf("TestMatrix failed for %q. Expected: %s, Got: %v %v", test.text, test.expect, result, err)
+		}
+	}
+}
+
+func TestMatrixModes(t *testing.T) {
+	tests := []struct {
+		mode   Mode
+		text   string
+		expect string
+	}{
+		{DecimalMode, "[0.1, 0.2] + 0.2", "[0.3, 0.4]"},
+		{DecimalMode, "[1 2; 3 4] ^ 0", "[1, 0; 0, 1]"},
+		{RationalMode, "[1 2; 3 4] / 3", "[1/3, 2/3; 1, 4/3]"},
+		{RationalMode, "[1/2, 1/3] * [1 0; 0 1]", "[1/2, 1/3]"},
+	}
+	for _, test := range tests {
+		node, err := parser.Parse(test.text)
+		if err != nil {
+			t.Fatalf("TestMatrixModes failed for %q. Got: %s", test.text, err)
+		}
+		ev := Evaluator{Mode: test.mode}
+		result, err := ev.Eval(node, nil)
+		if err != nil || result.String() != test.expect {
+			t.Errorf("TestMatrixModes failed for %q. Expected: %s, Got: %v %v", test.text, test.expect, result, err)
+		}
+	}
+}
+
+func TestMatrixErrors(t *testing.T) {
+	tests := []struct {
+		text         string
+		line, column int
+	}{
+		{"[1 2; 3]", 1, 7},
+		{"[1 2;\n 3 4 5]", 2, 2},
+		{"[1 true;]", 1, 4},
+		{"[1, 2] + [1, 2, 3]", 1, 8},
+		{"[1 2; 3 4] + [1 2 3;]", 1, 12},
+		{"[1 2 3; 4 5 6] * [1 2 3; 4 5 6]", 1, 16},
+		{"[1 2 3;] ^ 2", 1, 10},
+		{"[1 2; 3 4] ^ 0.5", 1, 12},
+		{"[2 0; 0 2] ^ (2 ^ 63)", 1, 12},
+		{"[2 0; 0 2] ^ 10 ^ 300", 1, 12},
+		{"[1 2; 3 4] / [1 2; 3 4]", 1, 12},
+		{"[1, 2] - [1 2; 3 4]", 1, 8},
+		{"[1, true] + 1", 1, 11},
+		{"-[1, true]", 1, 1},
+		{"[1, 2][3]", 1, 8},
+		{"[1, 2][0.5]", 1, 8},
+		{"[1, 2][true]", 1, 8},
+		{"[1, 2][1, 1]", 1, 7},
+		{"[1 2; 3 4][1, 3]", 1, 15},
+		{"2[1]", 1, 2},
+	}
+	for _, test := range tests {
+		result, err := run(test.text, nil)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("TestMatrixErrors failed for %q. Expected: *Error, Got: %v %v", test.text, result, err)
+			continue
+		}
+		if e.Line != test.line || e.Column != test.column {
+			t.Errorf("TestMatrixErrors failed for %q. Expected: line:%d column:%d, Got: %s", test.text, test.line, test.column, e)
+		}
+	}
+	large := []struct {
+		mode Mode
+		text string
+	}{
+		{RationalMode, "[9 0; 0 9] ^ (9 ^ 9)"},
+		{DecimalMode, "[10;] ^ 100000000"},
+		{DecimalMode, "[0.1 0; 0 1000] ^ 100000000"},
+	}
+	for _, test := range large {
+		ev := Evaluator{Mode: test.mode}
+		node, _ := parser.Parse(test.text)
+		result, err := ev.Eval(node, nil)
+		var e *TooLargeError
+		if !errors.As(err, &e) {
+			t.Errorf("TestMatrixErrors failed for %q. Expected: *TooLargeError, Got: %v %v", test.text, result, err)
+		}
+	}
+	_, err := run("[1 2 3; 4 5 6] * [1 2 3; 4 5 6]", nil)
+	expect := "cannot multiply 2×3 matrix by 2×3 matrix line:1 column:16"
+	if err == nil || err.Error() != expect {
+		t.Errorf("TestMatrixErrors failed. Expected: %s, Got: %v", expect, err)
+	}
+}
@@ -0,0 +1,141 @@
+package eval
+
+import (
+	"math/big"
+)
+
+// Rational is an exact fraction of arbitrary-precision integers.
+// Rationals are immutable. The zero value is 0.
+type Rational struct {
+	rat *big.Rat // Nil means zero.
+}
+
+// Outputs a Rational equal to "r". Later changes to "r" do not
+// affect the Rational.
+func NewRational(r *big.Rat) Rational {
+	return Rational{rat: new(big.Rat).Set(r)}
+}
+
+// Outputs a copy of the underlying fraction.
+func (r Rational) Rat() *big.Rat {
+	if r.rat == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(r.rat)
+}
+
+func (r Rational) ratio() *big.Rat {
+	if r.rat == nil {
+		return new(big.Rat)
+	}
+	return r.rat
+}
+
+// Outputs the fraction in lowest terms, such as "1/2", or the integer
+// alone if the denominator is 1.
+func (r Rational) String() string {
+	return r.ratio().RatString()
+}
+
+// Outputs the float64 nearest "r".
+func (r Rational) Float64() float64 {
+	f, _ := r.ratio().Float64()
+	return f
+}
+
+// Outputs the sign of "r": -1, 0, or +1.
+func (r Rational) Sign() int {
+	return r.ratio().Sign()
+}
+
+// Reports whether "r" is a whole number.
+func (r Rational) IsInt() bool {
+	return r.ratio().IsInt()
+}
+
+// Outputs -1 if r < s, 0 if r == s, and +1 if r > s.
+func (r Rational) Cmp(s Rational) int {
+	return r.ratio().Cmp(s.ratio())
+}
+
+// Outputs -r.
+func (r Rational) Neg() Rational {
+	return Rational{rat: new(big.Rat).Neg(r.ratio())}
+}
+
+// Outputs r + s.
+func (r Rational) Add(s Rational) Rational {
+	return Rational{rat: new(big.Rat).Add(r.ratio(), s.ratio())}
+}
+
+// Outputs r - s.
+func (r Rational) Sub(s Rational) Rational {
+	return Rational{rat: new(big.Rat).Sub(r.ratio(), s.ratio())}
+}
+
+// Outputs r × s.
+func (r Rational) Mul(s Rational) Rational {
+	return Rational{rat: new(big.Rat).Mul(r.ratio(), s.ratio())}
+}
+
+// Outputs r ÷ s. Division by zero is undefined.
+func (r Rational) Quo(s Rational) (Rational, error) {
+	if s.Sign() == 0 {
+		return Rational{}, errDivideByZero
+	}
+	return Rational{rat: new(big.Rat).Quo(r.ratio(), s.ratio())}, nil
+}
+
+// Outputs r^n for integer "n". Fails with a *TooLargeError rather
+// than compute a power of more than "maxDigits" digits.
+func (r Rational) PowInt(n int64) (Rational, error) {
+	x := r.ratio()
+	if n < 0 {
+		if x.Sign() == 0 {
+			return Rational{}, errDivideByZero
+		}
+		x = new(big.Rat).Inv(x)
+		n = -n
+	}
+	if (Rational{rat: x}).exceeds(n) {
+		return Rational{}, &TooLargeError{Op: "^"}
+	}
+	e := big.NewInt(n)
+	num := new(big.Int).Exp(x.Num(), e, nil)
+	den := new(big.Int).Exp(x.Denom(), e, nil)
+	return Rational{rat: new(big.Rat).SetFrac(num, den)}, nil
+}
+
+// Reports whether r^n, for natural number "n", has a numerator or
+// denominator of more than "maxDigits" digits.
+func (r Rational) exceeds(n int64) bool {
+	x := r.ratio()
+	return exceeds(max(x.Num().BitLen(), x.Denom().BitLen()), 0, n)
+}
+
+// Outputs the exact square root of "r", if one exists.
+func (r Rational) Sqrt() (Rational, bool) {
+	x := r.ratio()
+	if x.Sign() < 0 {
+		return Rational{}, false
+	}
+	num, ok := exactSqrt(x.Num())
+	if !ok {
+		return Rational{}, false
+	}
+	den, ok := exactSqrt(x.Denom())
+	if !ok {
+		return Rational{}, false
+	}
+	return Rational{rat: new(big.Rat).SetFrac(num, den)}, true
+}
+
+func exactSqrt(n *big.Int) (*big.Int, bool) {
+	s := new(big.Int).Sqrt(n)
+	return s, new(big.Int).Mul(s, s).Cmp(n) == 0
+}
+
+// Converts decimal "d" into an exact rational.
+func (d Decimal) Rational() Rational {
+	return Rational{rat: d.Rat()}
+}
@@ -0,0 +1,132 @@
+package eval
+
+import (
+	"errors"
+	"github/jared-richard-clarke/pratt/parser"
+	"testing"
+)
+
+func TestRationalEval(t *testing.T) {
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{"1/3 + 1/6", "1/2"},
+		{"1 ÷ 3 × 3", "1"},
+		{"0.1 + 0.2", "3/10"},
+		{"2^-2", "1/4"},
+		{"(2/3)^3", "8/27"},
+		{"(-1/2)^-3", "-8"},
+		{"10^20 + 1", "100000000000000000001"},
+		{"2x", "1/5"},
+		{"sqrt(4/9)", "2/3"},
+		{"abs(-3/4)", "3/4"},
+		{"floor(-7/2)", "-4"},
+		{"ceil(7/2)", "4"},
+		{"round(5/2)", "3"},
+		{"min(1/2, 1/3)", "1/3"},
+		{"sum(1/2, 1/3, 1/6)", "1"},
+		{"avg(1, 2)", "3/2"},
+		{"25!", "15511210043330985984000000"},
+		{"12.5%", "1/8"},
+	}
+	env := Env{"x": Number(0.1)}
+	ev := Evaluator{Mode: RationalMode}
+	for _, test := range tests {
+		node, err := parser.Parse(test.text)
+		if err != nil {
+			t.Fatalf("TestRationalEval failed for %q. Got: %s", test.text, err)
+		}
+		result, err := ev.Eval(node, env)
+		if err != nil {
+			t.Errorf("TestRationalEval failed for %q. Expected: %s, Got: %s", test.text, test.expect, err)
+			continue
+		}
+		if _, ok := result.(Rational); !ok {
+			t.Errorf("TestRationalEval failed for %q. Expected: Rational, Got: %T", test.text, result)
+		}
+		if result.String() != test.expect {
+			t.Errorf("TestRationalEval failed for %q. Expected: %s, Got: %s", test.text, test.expect, result)
+		}
+	}
+}
+
+func TestRationalEqual(t *testing.T) {
+	node, _ := parser.Parse("0.1 + 0.2 = 3/10")
+	ev := Evaluator{Mode: RationalMode}
+	result, err := ev.Eval(node, nil)
+	if err != nil || result != Bool(true) {
+		t.Errorf("TestRationalEqual failed. Expected: true, Got: %v %v", result, err)
+	}
+}
+
+func TestRationalInexact(t *testing.T) {
+	tests := []struct {
+		text string
+		op   string
+	}{
+		{"sqrt(2)", "sqrt"},
+		{"2^(1/2)", "^"},
+		{"2pi", "pi"},
+		{"sin(1)", "sin"},
+	}
+	for _, test := range tests {
+		node, _ := parser.Parse(test.text)
+		ev := Evaluator{Mode: RationalMode}
+		result, err := ev.Eval(node, nil)
+		var inexact *InexactError
+		if !errors.As(err, &inexact) {
+			t.Errorf("TestRationalInexact failed for %q. Expected: *InexactError, Got: %v %v", test.text, result, err)
+			continue
+		}
+		if inexact.Op != test.op {
+			t.Errorf("TestRationalInexact failed for %q. Expected: %s, Got: %s", test.text, test.op, inexact.Op)
+		}
+		ev.AllowInexact = true
+		result, err = ev.Eval(node, nil)
+		if err != nil {
+			t.Errorf("TestRationalInexact failed for %q. Expected: Number, Got: %s", test.text, err)
+			continue
+		}
+		if _, ok := result.(Number); !ok {
+			t.Errorf("TestRationalInexact failed for %q. Expected: Number, Got: %T", test.text, result)
+		}
+	}
+}
+
+func TestRationalTooLarge(t *testing.T) {
+	ev := Evaluator{Mode: RationalMode}
+	for _, text := range []string{"9^9^9", "(1/3)^-(9^9)"} {
+		node, _ := parser.Parse(text)
+		result, err := ev.Eval(node, nil)
+		var large *TooLargeError
+		if !errors.As(err, &large) {
+			t.Errorf("TestRationalTooLarge failed for %q. Expected: *TooLargeError, Got: %v %v", text, result, err)
+		}
+	}
+	for _, text := range []string{"99999999!", "(10^30)!"} {
+		node, _ := parser.Parse(text)
+		for _, mode := range []Mode{DecimalMode, RationalMode} {
+			ev := Evaluator{Mode: mode}
+			result, err := ev.Eval(node, nil)
+			var large *TooLargeError
+			if !errors.As(err, &large) || large.Op != "!" {
+				t.Errorf("TestRationalTooLarge failed for %q. Expected: *TooLargeError, Got: %v %v", text, result, err)
+			}
+		}
+	}
+	node, _ := parser.Parse("(-1)^(9^9)")
+	if result, err := ev.Eval(node, nil); err != nil || result.String() != "-1" {
+		t.Errorf("TestRationalTooLarge failed. Expected: -1, Got: %v %v", result, err)
+	}
+}
+
+func TestRationalDivideByZero(t *testing.T) {
+	for _, text := range []string{"1 ÷ (1/2 - 1/2)", "0^-1"} {
+		node, _ := parser.Parse(text)
+		ev := Evaluator{Mode: RationalMode}
+		if result, err := ev.Eval(node, nil); err == nil {
+			t.Errorf("TestRationalDivideByZero failed for %q. Expected: error, Got: %s", text, result)
+		}
+	}
+}
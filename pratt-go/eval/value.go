@@ -0,0 +1,57 @@
+package eval
+
+import (
+	"github/jared-richard-clarke/pratt/parser"
+	"strconv"
+	"strings"
+)
+
+// The interface that all values must satisfy.
+type Value interface {
+	String() string
+	value()
+}
+
+// Number evaluated as 64-bit floating point.
+type Number float64
+
+func (n Number) String() string {
+	return strconv.FormatFloat(float64(n), 'g', -1, 64)
+}
+
+// String literal, or the result of a concatenation or string function.
+type String string
+
+func (s String) String() string {
+	return strconv.Quote(string(s))
+}
+
+// Boolean literal, or the result of a comparison or logical operation.
+type Bool bool
+
+func (b Bool) String() string {
+	return strconv.FormatBool(bool(b))
+}
+
+// Function defined by a lambda, as in "x -> 2x", or by a function
+// definition, as in "f(x) := 2x". Captures the bindings of the program
+// in scope where it was defined.
+type Closure struct {
+	Params []string
+	Body   parser.Node
+	vars   Env
+}
+
+func (c *Closure) String() string {
+	return "(" + strings.Join(c.Params, ", ") + ") -> " + parser.Print(c.Body)
+}
+
+// value() is an empty method. It exists solely to group
+// selected types under the Value interface.
+
+func (n Number) value()   {}
+func (d Decimal) value()  {}
+func (r Rational) value() {}
+func (s String) value()   {}
+func (b Bool) value()     {}
+func (c *Closure) value() {}
@@ -1,3 +1,3 @@
 module github/jared-richard-clarke/pratt
 
-go 1.21
+go 1.23
@@ -0,0 +1,874 @@
+// Package lexer splits arithmetic source text into tokens: numbers,
+// strings, symbols, keywords, operators, and punctuators. Alternate spellings resolve to
+// a single type, so that '×' scans as "Mul" and '÷' as "Div", and
+// multiplication implied by juxtaposition, as in "2x", scans as an
+// "ImpMul" token occupying no text.
+package lexer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	newline        = '\n'
+	carriageReturn = '\r'
+	tab            = '\t'
+	whiteSpace     = ' '
+	decimalPoint   = '.'
+	underscore     = '_'
+)
+
+// Classifies tokens. Values are stable: new types are only ever added
+// after the last, so values may be stored.
+type LexType int
+
+const (
+	OpenParen LexType = iota
+	CloseParen
+	Comma
+	Equal
+	NotEqual
+	Add
+	Sub
+	Mul
+	ImpMul // implicit multiplier
+	Div
+	Pow
+	Number
+	Symbol
+	Illegal // unexpected character, produced only when recovering from errors
+	EOF
+	Less
+	Greater
+	LessEqual    // "<=" or "≤"
+	GreaterEqual // ">=" or "≥"
+	And          // "and", "∧", or "&&"
+	Or           // "or", "∨", or "||"
+	Not          // "not", "¬", or "!"
+	Boolean      // "true" or "false"
+	Factorial    // "!" immediately after an operand
+	Percent      // "%"
+	Prime        // "'" immediately after an operand, or "′"
+	Question
+	Colon
+	If
+	Then
+	Else
+	Semicolon // ";", or a line break where "Scanner.Semicolons" inserts one
+	Let
+	Define // ":="
+	Arrow  // "->" or "↦"
+	OpenBracket
+	CloseBracket
+	String // Quoted by '"' or "'". Value keeps the quotes and escapes.
+)
+
+// Text of each lexeme type, as output by "LexType.String". Operators
+// and punctuators map to their canonical spelling, others to their name.
+var LexNames = [...]string{
+	OpenParen:    "(",
+	CloseParen:   ")",
+	Comma:        ",",
+	Equal:        "=",
+	NotEqual:     "≠",
+	Add:          "+",
+	Sub:          "-",
+	Mul:          "*",
+	ImpMul:       "ImpMul",
+	Div:          "/",
+	Pow:          "^",
+	Number:       "Number",
+	Symbol:       "Symbol",
+	Illegal:      "Illegal",
+	EOF:          "EOF",
+	Less:         "<",
+	Greater:      ">",
+	LessEqual:    "<=",
+	GreaterEqual: ">=",
+	And:          "and",
+	Or:           "or",
+	Not:          "not",
+	Boolean:      "Boolean",
+	Factorial:    "!",
+	Percent:      "%",
+	Prime:        "'",
+	Question:     "?",
+	Colon:        ":",
+	If:           "if",
+	Then:         "then",
+	Else:         "else",
+	Semicolon:    ";",
+	Let:          "let",
+	Define:       ":=",
+	Arrow:        "->",
+	OpenBracket:  "[",
+	CloseBracket: "]",
+	String:       "String",
+}
+
+func (t LexType) String() string {
+	if t < 0 || int(t) >= len(LexNames) {
+		return fmt.Sprintf("LexType(%d)", int(t))
+	}
+	return LexNames[t]
+}
+
+type Token struct {
+	Typeof LexType // Lexeme type, denoted by "LexType".
+	Value  string  // Lexeme string value.
+	Line   int     // Lexeme line number. Counts newlines ('\n').
+	Column int     // Lexeme starting column within newline. Counts runes.
+	Offset int     // Lexeme starting offset within source. Counts bytes.
+	Size   int     // Lexeme length within source. Counts bytes.
+	Width  int     // Lexeme length within source. Counts runes. Differs from "Value" for alternate spellings.
+}
+
+// Locates a point within source text.
+type Position struct {
+	Filename string // Source file name, if any.
+	Offset   int    // Byte offset, starting at 0.
+	Line     int    // Line number, starting at 1. Counts newlines ('\n').
+	Column   int    // Column number, starting at 1. Counts runes.
+}
+
+func (p Position) String() string {
+	if p.Filename != "" {
+		return fmt.Sprintf("file:%s line:%d column:%d", p.Filename, p.Line, p.Column)
+	}
+	return fmt.Sprintf("line:%d column:%d", p.Line, p.Column)
+}
+
+// Describes a lexical error. Spans the offending text from "Pos" up to,
+// but not including, "End".
+type Error struct {
+	Pos   Position // Start of offending text.
+	End   Position // End of offending text.
+	Value string   // Offending text.
+	Msg   string   // Description of error.
+}
+
+func (e *Error) Error() string {
+	if e.Pos.Filename != "" {
+		return fmt.Sprintf("%s: %q file:%s line:%d, column:%d", e.Msg, e.Value, e.Pos.Filename, e.Pos.Line, e.Pos.Column)
+	}
+	return fmt.Sprintf("%s: %q line:%d, column:%d", e.Msg, e.Value, e.Pos.Line, e.Pos.Column)
+}
+
+// Helper functions and constants
+
+// Words that scan as operators or literals rather than symbols.
+var keywords = map[string]LexType{
+	"and":   And,
+	"or":    Or,
+	"not":   Not,
+	"true":  Boolean,
+	"false": Boolean,
+	"if":    If,
+	"then":  Then,
+	"else":  Else,
+	"let":   Let,
+}
+
+// Reports whether "t" is a keyword other than a literal. A keyword
+// operator after an operand, as in "2 and x", implies no multiplication.
+func isKeywordOp(t LexType) bool {
+	switch t {
+	case And, Or, Not, If, Then, Else, Let:
+		return true
+	}
+	return false
+}
+
+// Whereas lexer uses "EOF" to mark the end of an array of tokens,
+// lexer uses "eof" internally to signal the end of a string or file.
+// "eof" is the untyped int -1, which has no rune alias.
+const eof = -1
+
+func isAlphaNumeric(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == underscore
+}
+
+// Scanner and methods
+
+// A Scanner reads source text from an io.Reader and outputs its tokens
+// one at a time, holding no more than a lexeme and two runes of
+// lookahead in memory.
+type Scanner struct {
+	// If true, replaces each unexpected character with an "Illegal"
+	// token and records an error rather than stopping. Set before the
+	// first call to "Next".
+	Recover bool
+	// If true, scans each line break that follows an operand, outside
+	// parentheses, as a "Semicolon" token, so that lines may separate
+	// statements, unless the next line begins with an infix operator.
+	// Set before the first call to "Next".
+	Semicolons bool
+
+	reader io.RuneReader // Scanner input.
+	ahead  []lookahead   // Runes read from input but not yet consumed.
+	text   []rune        // Text of the current lexeme.
+	queue  []Token       // Tokens scanned but not yet output.
+	mul    *Token        // Implicit multiplier awaiting the next lexeme.
+	groups []group       // Parentheses and brackets open at the current lexeme, innermost last.
+	prev   Token         // Last token scanned, other than an implicit multiplier.
+	errors []*Error      // Lexical errors. Accumulate only when recovering.
+	err    error         // Error that stopped the scan, if any.
+	done   bool          // If true, the input is exhausted and EOF scanned.
+
+	byteOffset int // Total input offset. Counts bytes.
+	byteStart  int // Start of a lexeme within input. Counts bytes.
+	runeOffset int // Tracks the offset of a lexeme within a newline. Counts runes.
+	runeStart  int // Tracks the start of a lexeme within a newline. Counts runes.
+	line       int // Counts newlines ('\n').
+}
+
+// A group opened by a parenthesis or bracket.
+type group struct {
+	bracket bool // If true, opened by '['. Whitespace separates its elements.
+	lambda  bool // If true, holds an arrow, as in "(x -> 2x)".
+	call    bool // If true, holds the arguments of a call, as in "f(x)".
+}
+
+type lookahead struct {
+	r rune
+	w int // Width in bytes.
+}
+
+// Inputs a reader, outputs a Scanner over its text. Buffers the reader
+// unless it already reads runes.
+func NewScanner(r io.Reader) *Scanner {
+	rr, ok := r.(io.RuneReader)
+	if !ok {
+		rr = bufio.NewReader(r)
+	}
+	return &Scanner{
+		reader:     rr,
+		byteOffset: 0,
+		byteStart:  0,
+		runeOffset: 1,
+		runeStart:  1,
+		line:       1,
+	}
+}
+
+// Reads runes from input until "n" are ahead or input runs out.
+// Records any read error other than io.EOF.
+func (sc *Scanner) fill(n int) {
+	for len(sc.ahead) < n && sc.reader != nil {
+		r, w, err := sc.reader.ReadRune()
+		if err != nil {
+			if err != io.EOF && sc.err == nil {
+				sc.err = err
+			}
+			sc.reader = nil
+			return
+		}
+		sc.ahead = append(sc.ahead, lookahead{r, w})
+	}
+}
+
+func (sc *Scanner) end() bool {
+	sc.fill(1)
+	return len(sc.ahead) == 0
+}
+
+// Skips whitespace: '\t', '\n', '\v', '\f', '\r', ' ', U+0085 (NEL), U+00A0 (NBSP).
+// Stops at a line break that would scan as a semicolon.
+func (sc *Scanner) skip() {
+	for unicode.IsSpace(sc.peek()) {
+		if sc.peek() == newline && sc.insertSemicolon() {
+			return
+		}
+		if sc.next() == newline {
+			sc.line += 1
+			sc.runeOffset = 1
+			sc.runeStart = 1
+		}
+	}
+}
+
+func (sc *Scanner) next() rune {
+	if sc.end() {
+		return eof
+	}
+	a := sc.ahead[0]
+	sc.ahead = sc.ahead[1:]
+	sc.runeOffset += 1
+	sc.byteOffset += a.w
+	sc.text = append(sc.text, a.r)
+	return a.r
+}
+
+func (sc *Scanner) peek() rune {
+	if sc.end() {
+		return eof
+	}
+	return sc.ahead[0].r
+}
+
+func (sc *Scanner) peekNext() rune {
+	sc.fill(2)
+	if len(sc.ahead) < 2 {
+		return eof
+	}
+	return sc.ahead[1].r
+}
+
+func (sc *Scanner) addToken(t LexType, v string) {
+	if sc.mul != nil {
+		if !isKeywordOp(t) {
+			sc.queue = append(sc.queue, *sc.mul)
+		}
+		sc.mul = nil
+	}
+	sc.prev = Token{
+		Typeof: t,
+		Value:  v,
+		Line:   sc.line,
+		Column: sc.runeStart,
+		Offset: sc.byteStart,
+		Size:   sc.byteOffset - sc.byteStart,
+		Width:  sc.runeOffset - sc.runeStart,
+	}
+	sc.queue = append(sc.queue, sc.prev)
+}
+
+// Reports whether the current lexeme immediately follows an operand,
+// with no space between. Only then does '!' read as a factorial.
+func (sc *Scanner) follows() bool {
+	return sc.prev.Offset+sc.prev.Size == sc.byteStart && endsOperand(sc.prev.Typeof)
+}
+
+// Reports whether a line break at the current position would scan
+// as a semicolon: it follows an operand, outside parentheses, and the
+// next line does not continue the statement.
+func (sc *Scanner) insertSemicolon() bool {
+	return sc.Semicolons && len(sc.groups) == 0 && endsOperand(sc.prev.Typeof) && !sc.continues()
+}
+
+// Reports whether the next line, past any blank lines, begins with an
+// infix or ternary operator, which continues the statement before it,
+// as in "c\n? x\n: y". A minus sign may negate, so begins a statement.
+func (sc *Scanner) continues() bool {
+	i := 0
+	for {
+		sc.fill(i + 1)
+		if i == len(sc.ahead) {
+			return false
+		}
+		if !unicode.IsSpace(sc.ahead[i].r) {
+			break
+		}
+		i++
+	}
+	r := sc.ahead[i].r
+	if strings.ContainsRune("+*×/÷^=≠<>≤≥∧∨↦?:", r) {
+		return true
+	}
+	sc.fill(i + 2)
+	if i+1 < len(sc.ahead) {
+		switch string([]rune{r, sc.ahead[i+1].r}) {
+		case "&&", "||", "!=", "->":
+			return true
+		}
+	}
+	// A word continues the statement if it is an infix keyword. Reads
+	// no further than the longest.
+	var word []rune
+	for j := i; len(word) <= len("then"); j++ {
+		sc.fill(j + 1)
+		if j == len(sc.ahead) || !isAlphaNumeric(sc.ahead[j].r) {
+			break
+		}
+		word = append(word, sc.ahead[j].r)
+	}
+	switch keywords[string(word)] {
+	case And, Or, Then, Else:
+		return true
+	}
+	return false
+}
+
+// Reports whether whitespace at the current position would separate
+// elements: it follows an operand, directly within brackets.
+func (sc *Scanner) insertComma() bool {
+	n := len(sc.groups)
+	return n > 0 && sc.groups[n-1].bracket && endsOperand(sc.prev.Typeof)
+}
+
+// Scans whitespace "r", and any that follows, between the elements of
+// a bracketed list as a comma, so that "[1 2]" reads "[1, 2]". Like an
+// implicit multiplier, the comma is held until the next lexeme, since
+// an operator rather than an operand may follow.
+func (sc *Scanner) separate(r rune) {
+	comma := &Token{
+		Typeof: Comma,
+		Value:  " ",
+		Line:   sc.line,
+		Column: sc.runeStart,
+		Offset: sc.byteStart,
+		Size:   sc.byteOffset - sc.byteStart,
+		Width:  sc.runeOffset - sc.runeStart,
+	}
+	if r == newline {
+		sc.line += 1
+		sc.runeOffset = 1
+		sc.runeStart = 1
+	}
+	sc.skip()
+	if c := sc.peek(); unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("([\"'", c) {
+		sc.mul = comma
+	}
+}
+
+// Reports whether a token of type "t" may end an operand: a number,
+// symbol, literal, closing parenthesis or bracket, or postfix operator.
+func endsOperand(t LexType) bool {
+	switch t {
+	case Number, String, Symbol, Boolean, CloseParen, CloseBracket, Factorial, Percent, Prime:
+		return true
+	}
+	return false
+}
+
+// Adds an implicit multiplier if the next lexeme begins with a character
+// in "next" and is not a keyword operator. Positions the multiplier
+// immediately after the previous lexeme, where an explicit operator
+// would be written.
+func (sc *Scanner) implyMul(next func(rune) bool) {
+	mul := &Token{
+		Typeof: ImpMul,
+		Value:  "*",
+		Line:   sc.line,
+		Column: sc.runeOffset,
+		Offset: sc.byteOffset,
+	}
+	if sc.insertComma() && unicode.IsSpace(sc.peek()) {
+		// Within brackets, whitespace separates elements instead.
+		return
+	}
+	sc.skip()
+	if next(sc.peek()) {
+		// Held until the next lexeme is scanned, since a word may be
+		// a keyword.
+		sc.mul = mul
+	}
+}
+
+// Closes the innermost open group, if any, and outputs it.
+func (sc *Scanner) close() group {
+	n := len(sc.groups)
+	if n == 0 {
+		return group{}
+	}
+	g := sc.groups[n-1]
+	sc.groups = sc.groups[:n-1]
+	return g
+}
+
+// Adds an arrow, marking the innermost open group as a function.
+func (sc *Scanner) arrow() {
+	if n := len(sc.groups); n > 0 {
+		sc.groups[n-1].lambda = true
+	}
+	sc.addToken(Arrow, "->")
+}
+
+// Scans the rest of a string literal opened by "quote". Escapes follow
+// Go, as in "\n", "\x41", or "\u00e9", and the opening quote may be
+// escaped within. A string ends at its line.
+func (sc *Scanner) scanString(quote rune) *Error {
+	for {
+		switch sc.peek() {
+		case quote:
+			sc.next()
+			sc.addToken(String, string(sc.text))
+			return nil
+		case eof, newline:
+			// Reported at the opening quote, which went unmatched.
+			return sc.errorSince(sc.start(), 0, "unterminated string")
+		case '\\':
+			if err := sc.escape(quote); err != nil {
+				return err
+			}
+		default:
+			sc.next()
+		}
+	}
+}
+
+// Scans an escape sequence within a string opened by "quote".
+func (sc *Scanner) escape(quote rune) *Error {
+	pos := sc.position()
+	i := len(sc.text)
+	sc.next()
+	n := 1
+	switch c := sc.peek(); {
+	case c == 'x':
+		n = 3
+	case c == 'u':
+		n = 5
+	case c == 'U':
+		n = 9
+	case '0' <= c && c <= '7':
+		n = 3
+	}
+	for k := 0; k < n; k++ {
+		if c := sc.peek(); c == eof || c == newline || (k > 0 && c == quote) {
+			break
+		}
+		sc.next()
+	}
+	_, _, tail, err := strconv.UnquoteChar(string(sc.text[i:]), byte(quote))
+	if err != nil || tail != "" {
+		return sc.errorSince(pos, i, "invalid escape sequence")
+	}
+	return nil
+}
+
+// Outputs the start of the current lexeme.
+func (sc *Scanner) start() Position {
+	return Position{
+		Offset: sc.byteStart,
+		Line:   sc.line,
+		Column: sc.runeStart,
+	}
+}
+
+// Outputs the current position.
+func (sc *Scanner) position() Position {
+	return Position{
+		Offset: sc.byteOffset,
+		Line:   sc.line,
+		Column: sc.runeOffset,
+	}
+}
+
+// Outputs an error spanning the text from "pos" to the current position,
+// which begins at index "i" of the current lexeme.
+func (sc *Scanner) errorSince(pos Position, i int, msg string) *Error {
+	return &Error{
+		Pos:   pos,
+		End:   sc.position(),
+		Value: string(sc.text[i:]),
+		Msg:   msg,
+	}
+}
+
+func (sc *Scanner) scanToken() *Error {
+	r := sc.next()
+	switch {
+	// whitespace
+	case (r == whiteSpace || r == carriageReturn || r == tab || r == newline) && sc.insertComma():
+		sc.separate(r)
+		return nil
+	case r == whiteSpace, r == carriageReturn, r == tab:
+		return nil
+	case r == newline:
+		if sc.insertSemicolon() {
+			sc.addToken(Semicolon, "\n")
+		}
+		sc.runeOffset = 1
+		sc.runeStart = 1
+		sc.line += 1
+		return nil
+	// punctuators
+	case r == '(':
+		// An operand followed by a parenthesis, and not multiplied by it,
+		// is called.
+		call := sc.mul == nil && endsOperand(sc.prev.Typeof)
+		sc.groups = append(sc.groups, group{call: call})
+		sc.addToken(OpenParen, "(")
+		return nil
+	case r == ')':
+		g := sc.close()
+		sc.addToken(CloseParen, ")")
+		// Check for implied multiplication: (7+11)x, (7+11)(11+7), or (7+11)7.
+		// A parenthesized function, as in (x -> 2x)(3), is called instead,
+		// as is the result of a call directly followed by a parenthesis,
+		// as in k(1)(2).
+		called := g.lambda || (g.call && sc.peek() == '(')
+		sc.implyMul(func(c rune) bool {
+			return unicode.IsLetter(c) || unicode.IsDigit(c) || (c == '(' && !called)
+		})
+		return nil
+	case r == '[':
+		sc.groups = append(sc.groups, group{bracket: true})
+		sc.addToken(OpenBracket, "[")
+		return nil
+	case r == ']':
+		sc.close()
+		sc.addToken(CloseBracket, "]")
+		return nil
+	case r == ',':
+		sc.addToken(Comma, ",")
+		return nil
+	case r == '-':
+		if sc.peek() == '>' {
+			sc.next()
+			sc.arrow()
+			return nil
+		}
+		sc.addToken(Sub, "-")
+		return nil
+	case r == '↦':
+		sc.arrow()
+		return nil
+	case r == '+':
+		sc.addToken(Add, "+")
+		return nil
+	case r == '*' || r == '×':
+		sc.addToken(Mul, "*")
+		return nil
+	case r == '/' || r == '÷':
+		sc.addToken(Div, "/")
+		return nil
+	case r == '^':
+		sc.addToken(Pow, "^")
+		return nil
+	case r == '=':
+		sc.addToken(Equal, "=")
+		return nil
+	case r == '≠':
+		sc.addToken(NotEqual, "≠")
+		return nil
+	case r == '<':
+		if sc.peek() == '=' {
+			sc.next()
+			sc.addToken(LessEqual, "<=")
+			return nil
+		}
+		sc.addToken(Less, "<")
+		return nil
+	case r == '>':
+		if sc.peek() == '=' {
+			sc.next()
+			sc.addToken(GreaterEqual, ">=")
+			return nil
+		}
+		sc.addToken(Greater, ">")
+		return nil
+	case r == '≤':
+		sc.addToken(LessEqual, "<=")
+		return nil
+	case r == '≥':
+		sc.addToken(GreaterEqual, ">=")
+		return nil
+	case r == '!':
+		if sc.peek() == '=' {
+			sc.next()
+			sc.addToken(NotEqual, "≠")
+			return nil
+		}
+		if sc.follows() {
+			sc.addToken(Factorial, "!")
+			// Check for implied multiplication: 3!x or 3!(x)
+			sc.implyMul(func(c rune) bool {
+				return unicode.IsLetter(c) || c == '('
+			})
+			return nil
+		}
+		sc.addToken(Not, "not")
+		return nil
+	case r == '%':
+		sc.addToken(Percent, "%")
+		// Check for implied multiplication: 50%x or 50%(x)
+		sc.implyMul(func(c rune) bool {
+			return unicode.IsLetter(c) || c == '('
+		})
+		return nil
+	// strings
+	case r == '"', r == '\'' && !sc.follows():
+		return sc.scanString(r)
+	case r == '\'' || r == '′':
+		sc.addToken(Prime, "'")
+		return nil
+	case r == '?':
+		sc.addToken(Question, "?")
+		return nil
+	case r == ':':
+		if sc.peek() == '=' {
+			sc.next()
+			sc.addToken(Define, ":=")
+			return nil
+		}
+		sc.addToken(Colon, ":")
+		return nil
+	case r == ';':
+		sc.addToken(Semicolon, ";")
+		return nil
+	case r == '¬':
+		sc.addToken(Not, "not")
+		return nil
+	case r == '∧':
+		sc.addToken(And, "and")
+		return nil
+	case r == '∨':
+		sc.addToken(Or, "or")
+		return nil
+	case r == '&' && sc.peek() == '&':
+		sc.next()
+		sc.addToken(And, "and")
+		return nil
+	case r == '|' && sc.peek() == '|':
+		sc.next()
+		sc.addToken(Or, "or")
+		return nil
+	// numbers
+	case unicode.IsDigit(r):
+		for unicode.IsDigit(sc.peek()) {
+			sc.next()
+		}
+		if sc.peek() == decimalPoint && unicode.IsDigit(sc.peekNext()) {
+			sc.next()
+			for unicode.IsDigit(sc.peek()) {
+				sc.next()
+			}
+		}
+		text := string(sc.text)
+		sc.addToken(Number, text)
+		// Check for implied multiplication: 7x or 7(7+11)
+		sc.implyMul(func(c rune) bool {
+			return unicode.IsLetter(c) || c == '('
+		})
+		return nil
+	// symbols
+	case unicode.IsLetter(r):
+		for isAlphaNumeric(sc.peek()) {
+			sc.next()
+		}
+		text := string(sc.text)
+		if t, ok := keywords[text]; ok {
+			sc.addToken(t, text)
+			return nil
+		}
+		sc.addToken(Symbol, text)
+		// Check for implied multiplication: pi r. A symbol followed
+		// by a parenthesis is a call.
+		sc.implyMul(unicode.IsLetter)
+		return nil
+	// undefined
+	default:
+		return sc.errorSince(sc.start(), 0, "unexpected character")
+	}
+}
+
+// Scans the next lexeme, adding any tokens it yields to the queue.
+// Once input is exhausted, or a lexical error stops the scan, adds EOF.
+func (sc *Scanner) scan() {
+	if sc.done || sc.err != nil || sc.end() {
+		sc.done = true
+		sc.queue = append(sc.queue, Token{
+			Typeof: EOF,
+			Line:   sc.line,
+			Column: sc.runeOffset,
+			Offset: sc.byteOffset,
+		})
+		return
+	}
+	sc.byteStart = sc.byteOffset
+	sc.runeStart = sc.runeOffset
+	sc.text = sc.text[:0]
+	if err := sc.scanToken(); err != nil {
+		if !sc.Recover {
+			sc.err = err
+			return
+		}
+		// Record error, then mark its place with an "Illegal" token.
+		sc.errors = append(sc.errors, err)
+		sc.addToken(Illegal, err.Value)
+	}
+}
+
+// Consumes and outputs the next token. Once input is exhausted, outputs
+// EOF forever. Stops at the first lexical error, which "Err" reports,
+// unless recovering.
+func (sc *Scanner) Next() Token {
+	for len(sc.queue) == 0 {
+		sc.scan()
+	}
+	t := sc.queue[0]
+	sc.queue = sc.queue[1:]
+	return t
+}
+
+// Outputs every remaining token, ending with EOF.
+func (sc *Scanner) Tokens() iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		for {
+			t := sc.Next()
+			if !yield(t) || t.Typeof == EOF {
+				return
+			}
+		}
+	}
+}
+
+// Outputs the error that stopped the scan: either a lexical *Error or
+// an error reading input. Outputs nil if there is none.
+func (sc *Scanner) Err() error {
+	return sc.err
+}
+
+// Outputs the lexical errors recorded so far while recovering.
+func (sc *Scanner) Errors() []*Error {
+	return sc.errors
+}
+
+// Inputs the text of a "String" token, outputs the string it denotes,
+// without quotes and with its escapes interpreted.
+func Unquote(s string) (string, error) {
+	n := len(s)
+	if n < 2 || s[0] != s[n-1] || (s[0] != '"' && s[0] != '\'') {
+		return "", strconv.ErrSyntax
+	}
+	quote := s[0]
+	s = s[1 : n-1]
+	var b strings.Builder
+	for len(s) > 0 {
+		r, multibyte, tail, err := strconv.UnquoteChar(s, quote)
+		if err != nil {
+			return "", err
+		}
+		if r < utf8.RuneSelf || !multibyte {
+			b.WriteByte(byte(r))
+		} else {
+			b.WriteRune(r)
+		}
+		s = tail
+	}
+	return b.String(), nil
+}
+
+// The Lexer API: drives the scanner. Stops at the first lexical error.
+func Scan(t string) ([]Token, error) {
+	sc := NewScanner(strings.NewReader(t))
+	tokens := make([]Token, 0)
+	for token := range sc.Tokens() {
+		tokens = append(tokens, token)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Like "Scan" but never stops early. Replaces each unexpected character
+// with an "Illegal" token and outputs every lexical error alongside
+// the complete token slice.
+func ScanAll(t string) ([]Token, []*Error) {
+	sc := NewScanner(strings.NewReader(t))
+	sc.Recover = true
+	tokens := make([]Token, 0)
+	for token := range sc.Tokens() {
+		tokens = append(tokens, token)
+	}
+	return tokens, sc.Errors()
+}
@@ -0,0 +1,1027 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func (t Token) String() string {
+	switch {
+	case t.Typeof == ImpMul:
+		return fmt.Sprintf("punct: \"imp-*\" :%d:%d", t.Line, t.Column)
+	case t.Typeof == String:
+		return fmt.Sprintf("string: %s :%d:%d", t.Value, t.Line, t.Column)
+	case t.Typeof < Number, t.Typeof > EOF:
+		return fmt.Sprintf("punct: %q :%d:%d", t.Value, t.Line, t.Column)
+	case t.Typeof == Number:
+		return fmt.Sprintf("number: %q :%d:%d", t.Value, t.Line, t.Column)
+	case t.Typeof == Symbol:
+		return fmt.Sprintf("symbol: %q :%d:%d", t.Value, t.Line, t.Column)
+	default:
+		return fmt.Sprintf("<eof> :%d:%d", t.Line, t.Column)
+	}
+}
+
+func TestScan(t *testing.T) {
+	text := "1 + 2 * 3"
+	expect := []Token{
+		{
+			Typeof: Number,
+			Value:  "1",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Add,
+			Value:  "+",
+			Line:   1,
+			Column: 3,
+			Offset: 2,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "2",
+			Line:   1,
+			Column: 5,
+			Offset: 4,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Mul,
+			Value:  "*",
+			Line:   1,
+			Column: 7,
+			Offset: 6,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "3",
+			Line:   1,
+			Column: 9,
+			Offset: 8,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(1, 10, 9),
+	}
+	result, _ := Scan(text)
+	compare(expect, result, t, "Scan")
+}
+
+func TestEmpty(t *testing.T) {
+	text := " \n\t"
+	expect := []Token{mkEof(2, 2, 3)}
+	result, _ := Scan(text)
+	compare(expect, result, t, "Empty")
+}
+
+func TestParens(t *testing.T) {
+	text := "(1 + 2) * 3"
+	expect := []Token{
+		{
+			Typeof: OpenParen,
+			Value:  "(",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "1",
+			Line:   1,
+			Column: 2,
+			Offset: 1,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Add,
+			Value:  "+",
+			Line:   1,
+			Column: 4,
+			Offset: 3,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "2",
+			Line:   1,
+			Column: 6,
+			Offset: 5,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: CloseParen,
+			Value:  ")",
+			Line:   1,
+			Column: 7,
+			Offset: 6,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Mul,
+			Value:  "*",
+			Line:   1,
+			Column: 9,
+			Offset: 8,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "3",
+			Line:   1,
+			Column: 11,
+			Offset: 10,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(1, 12, 11),
+	}
+	result, _ := Scan(text)
+	compare(expect, result, t, "Parens")
+}
+
+func TestComma(t *testing.T) {
+	text := "op(2, 5)"
+	expect := []Token{
+		{
+			Typeof: Symbol,
+			Value:  "op",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   2,
+			Width:  2,
+		},
+		{
+			Typeof: OpenParen,
+			Value:  "(",
+			Line:   1,
+			Column: 3,
+			Offset: 2,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "2",
+			Line:   1,
+			Column: 4,
+			Offset: 3,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Comma,
+			Value:  ",",
+			Line:   1,
+			Column: 5,
+			Offset: 4,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "5",
+			Line:   1,
+			Column: 7,
+			Offset: 6,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: CloseParen,
+			Value:  ")",
+			Line:   1,
+			Column: 8,
+			Offset: 7,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(1, 9, 8),
+	}
+	result, _ := Scan(text)
+	compare(expect, result, t, "Comma")
+}
+
+func TestNewlines(t *testing.T) {
+	text := "1 + 2\n * 3"
+	expect := []Token{
+		{
+			Typeof: Number,
+			Value:  "1",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Add,
+			Value:  "+",
+			Line:   1,
+			Column: 3,
+			Offset: 2,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "2",
+			Line:   1,
+			Column: 5,
+			Offset: 4,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Mul,
+			Value:  "*",
+			Line:   2,
+			Column: 2,
+			Offset: 7,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "3",
+			Line:   2,
+			Column: 4,
+			Offset: 9,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(2, 5, 10),
+	}
+	result, _ := Scan(text)
+	compare(expect, result, t, "Newlines (1)")
+
+	text = `1 +
+	        2 *
+	        3`
+	expect = []Token{
+		{
+			Typeof: Number,
+			Value:  "1",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Add,
+			Value:  "+",
+			Line:   1,
+			Column: 3,
+			Offset: 2,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "2",
+			Line:   2,
+			Column: 10,
+			Offset: 13,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Mul,
+			Value:  "*",
+			Line:   2,
+			Column: 12,
+			Offset: 15,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "3",
+			Line:   3,
+			Column: 10,
+			Offset: 26,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(3, 11, 27),
+	}
+	result, _ = Scan(text)
+	compare(expect, result, t, "Newlines (2)")
+}
+
+func TestSymbol(t *testing.T) {
+	text := "x + wyvern * 3"
+	expect := []Token{
+		{
+			Typeof: Symbol,
+			Value:  "x",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Add,
+			Value:  "+",
+			Line:   1,
+			Column: 3,
+			Offset: 2,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Symbol,
+			Value:  "wyvern",
+			Line:   1,
+			Column: 5,
+			Offset: 4,
+			Size:   6,
+			Width:  6,
+		},
+		{
+			Typeof: Mul,
+			Value:  "*",
+			Line:   1,
+			Column: 12,
+			Offset: 11,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "3",
+			Line:   1,
+			Column: 14,
+			Offset: 13,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(1, 15, 14),
+	}
+	result, _ := Scan(text)
+	compare(expect, result, t, "Symbol (1)")
+
+	text = "x + wyvern/hamster"
+	expect = []Token{
+		{
+			Typeof: Symbol,
+			Value:  "x",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Add,
+			Value:  "+",
+			Line:   1,
+			Column: 3,
+			Offset: 2,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Symbol,
+			Value:  "wyvern",
+			Line:   1,
+			Column: 5,
+			Offset: 4,
+			Size:   6,
+			Width:  6,
+		},
+		{
+			Typeof: Div,
+			Value:  "/",
+			Line:   1,
+			Column: 11,
+			Offset: 10,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Symbol,
+			Value:  "hamster",
+			Line:   1,
+			Column: 12,
+			Offset: 11,
+			Size:   7,
+			Width:  7,
+		},
+		mkEof(1, 19, 18),
+	}
+	result, _ = Scan(text)
+	compare(expect, result, t, "Symbol (2)")
+}
+
+func TestNumbers(t *testing.T) {
+	text := "7.5/2"
+	expect := []Token{
+		{
+			Typeof: Number,
+			Value:  "7.5",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   3,
+			Width:  3,
+		},
+		{
+			Typeof: Div,
+			Value:  "/",
+			Line:   1,
+			Column: 4,
+			Offset: 3,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "2",
+			Line:   1,
+			Column: 5,
+			Offset: 4,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(1, 6, 5),
+	}
+	result, _ := Scan(text)
+	compare(expect, result, t, "Numbers (1)")
+
+	text = "1024"
+	expect = []Token{
+		{
+			Typeof: Number,
+			Value:  "1024",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   4,
+			Width:  4,
+		},
+		mkEof(1, 5, 4),
+	}
+	result, _ = Scan(text)
+	compare(expect, result, t, "Numbers (2)")
+}
+
+func TestSub(t *testing.T) {
+	text := "1 - -2"
+	expect := []Token{
+		{
+			Typeof: Number,
+			Value:  "1",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Sub,
+			Value:  "-",
+			Line:   1,
+			Column: 3,
+			Offset: 2,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Sub,
+			Value:  "-",
+			Line:   1,
+			Column: 5,
+			Offset: 4,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "2",
+			Line:   1,
+			Column: 6,
+			Offset: 5,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(1, 7, 6),
+	}
+	result, _ := Scan(text)
+	compare(expect, result, t, "Sub")
+}
+
+// A Token of type ImpMul occupies no text. It is positioned
+// immediately after the previous token.
+func TestImpMul(t *testing.T) {
+	text := "2x"
+	expect := []Token{
+		{
+			Typeof: Number,
+			Value:  "2",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: ImpMul,
+			Value:  "*",
+			Line:   1,
+			Column: 2,
+			Offset: 1,
+			Size:   0,
+			Width:  0,
+		},
+		{
+			Typeof: Symbol,
+			Value:  "x",
+			Line:   1,
+			Column: 2,
+			Offset: 1,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(1, 3, 2),
+	}
+	result, _ := Scan(text)
+	compare(result, expect, t, "ImpMul (1)")
+
+	text = "(x)y"
+	expect = []Token{
+		{
+			Typeof: OpenParen,
+			Value:  "(",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Symbol,
+			Value:  "x",
+			Line:   1,
+			Column: 2,
+			Offset: 1,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: CloseParen,
+			Value:  ")",
+			Line:   1,
+			Column: 3,
+			Offset: 2,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: ImpMul,
+			Value:  "*",
+			Line:   1,
+			Column: 4,
+			Offset: 3,
+			Size:   0,
+			Width:  0,
+		},
+		{
+			Typeof: Symbol,
+			Value:  "y",
+			Line:   1,
+			Column: 4,
+			Offset: 3,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(1, 5, 4),
+	}
+	result, _ = Scan(text)
+	compare(result, expect, t, "ImpMul (2)")
+}
+
+func TestPow(t *testing.T) {
+	text := "4^2"
+	expect := []Token{
+		{
+			Typeof: Number,
+			Value:  "4",
+			Line:   1,
+			Column: 1,
+			Offset: 0,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Pow,
+			Value:  "^",
+			Line:   1,
+			Column: 2,
+			Offset: 1,
+			Size:   1,
+			Width:  1,
+		},
+		{
+			Typeof: Number,
+			Value:  "2",
+			Line:   1,
+			Column: 3,
+			Offset: 2,
+			Size:   1,
+			Width:  1,
+		},
+		mkEof(1, 4, 3),
+	}
+	result, _ := Scan(text)
+	compare(expect, result, t, "Pow")
+}
+
+func TestComparison(t *testing.T) {
+	text := "0 ≤ x<=1 < y ≥ z>=2 > w"
+	expect := []LexType{Number, LessEqual, Symbol, LessEqual, Number, Less, Symbol, GreaterEqual, Symbol, GreaterEqual, Number, Greater, Symbol, EOF}
+	values := map[LexType]string{
+		Less:         "<",
+		Greater:      ">",
+		LessEqual:    "<=",
+		GreaterEqual: ">=",
+	}
+	result, err := Scan(text)
+	if err != nil || len(result) != len(expect) {
+		t.Fatalf("Test Comparison failed. Expected: %v, Got: %v %v", expect, result, err)
+	}
+	for i, token := range result {
+		if token.Typeof != expect[i] {
+			t.Errorf("Test Comparison failed. Expected: %s, Got: %v", expect[i], token)
+		}
+		if v, ok := values[token.Typeof]; ok && token.Value != v {
+			t.Errorf("Test Comparison failed. Expected: %q, Got: %v", v, token)
+		}
+	}
+	// "≤" spans 3 bytes, "<=" 2.
+	if result[1].Size != 3 || result[3].Size != 2 {
+		t.Errorf("Test Comparison failed. Expected: sizes 3 and 2, Got: %d and %d", result[1].Size, result[3].Size)
+	}
+}
+
+func TestLogical(t *testing.T) {
+	text := "a and b ∧ c && not d ¬e !f or g ∨ h || 2 and true != false"
+	expect := []LexType{Symbol, And, Symbol, And, Symbol, And, Not, Symbol, Not, Symbol, Not, Symbol, Or, Symbol, Or, Symbol, Or, Number, And, Boolean, NotEqual, Boolean, EOF}
+	values := map[LexType]string{
+		And:      "and",
+		Or:       "or",
+		Not:      "not",
+		NotEqual: "≠",
+	}
+	result, err := Scan(text)
+	if err != nil || len(result) != len(expect) {
+		t.Fatalf("Test Logical failed. Expected: %v, Got: %v %v", expect, result, err)
+	}
+	for i, token := range result {
+		if token.Typeof != expect[i] {
+			t.Errorf("Test Logical failed. Expected: %s, Got: %v", expect[i], token)
+		}
+		if v, ok := values[token.Typeof]; ok && token.Value != v {
+			t.Errorf("Test Logical failed. Expected: %q, Got: %v", v, token)
+		}
+	}
+	// Keywords are not multiplied by juxtaposition. Literals are.
+	result, _ = Scan("(x)or 2true")
+	expect = []LexType{OpenParen, Symbol, CloseParen, Or, Number, ImpMul, Boolean, EOF}
+	if len(result) != len(expect) {
+		t.Fatalf("Test Logical failed. Expected: %v, Got: %v", expect, result)
+	}
+	for i, token := range result {
+		if token.Typeof != expect[i] {
+			t.Errorf("Test Logical failed. Expected: %s, Got: %v", expect[i], token)
+		}
+	}
+	// A lone '&' is not an operator.
+	if _, err := Scan("a & b"); err == nil {
+		t.Errorf("Test Logical failed. Expected: error for %q", "a & b")
+	}
+}
+
+func TestPostfix(t *testing.T) {
+	tests := []struct {
+		text   string
+		expect []LexType
+	}{
+		{"3!x", []LexType{Number, Factorial, ImpMul, Symbol, EOF}},
+		{"2^3!", []LexType{Number, Pow, Number, Factorial, EOF}},
+		{"(n)!!", []LexType{OpenParen, Symbol, CloseParen, Factorial, Factorial, EOF}},
+		{"50%(x)", []LexType{Number, Percent, ImpMul, OpenParen, Symbol, CloseParen, EOF}},
+		{"f'(x) + g′′(x)", []LexType{Symbol, Prime, OpenParen, Symbol, CloseParen, Add, Symbol, Prime, Prime, OpenParen, Symbol, CloseParen, EOF}},
+		// Apart from its operand, or at the start, '!' is logical negation.
+		{"x !y", []LexType{Symbol, Not, Symbol, EOF}},
+		{"!x!", []LexType{Not, Symbol, Factorial, EOF}},
+		{"x!=y", []LexType{Symbol, NotEqual, Symbol, EOF}},
+	}
+	for _, test := range tests {
+		result, err := Scan(test.text)
+		if err != nil || len(result) != len(test.expect) {
+			t.Errorf("Test Postfix failed for %q. Expected: %v, Got: %v %v", test.text, test.expect, result, err)
+			continue
+		}
+		for i, token := range result {
+			if token.Typeof != test.expect[i] {
+				t.Errorf("Test Postfix failed for %q. Expected: %s, Got: %v", test.text, test.expect[i], token)
+			}
+		}
+	}
+}
+
+func TestConditional(t *testing.T) {
+	text := "x>0 ? 2x : if y then 3 else(z)"
+	expect := []LexType{Symbol, Greater, Number, Question, Number, ImpMul, Symbol, Colon, If, Symbol, Then, Number, Else, OpenParen, Symbol, CloseParen, EOF}
+	result, err := Scan(text)
+	if err != nil || len(result) != len(expect) {
+		t.Fatalf("Test Conditional failed. Expected: %v, Got: %v %v", expect, result, err)
+	}
+	for i, token := range result {
+		if token.Typeof != expect[i] {
+			t.Errorf("Test Conditional failed. Expected: %s, Got: %v", expect[i], token)
+		}
+	}
+}
+
+func TestSemicolons(t *testing.T) {
+	text := "let r = 3; area = pi r^2\n\n(1 +\n 2)\nx -\ny\n2\nz"
+	expect := []LexType{Let, Symbol, Equal, Number, Semicolon, Symbol, Equal, Symbol, ImpMul, Symbol, Pow, Number, Semicolon, OpenParen, Number, Add, Number, CloseParen, Semicolon, Symbol, Sub, Symbol, Semicolon, Number, Semicolon, Symbol, EOF}
+	sc := NewScanner(strings.NewReader(text))
+	sc.Semicolons = true
+	var result []Token
+	for token := range sc.Tokens() {
+		result = append(result, token)
+	}
+	if sc.Err() != nil || len(result) != len(expect) {
+		t.Fatalf("Test Semicolons failed. Expected: %v, Got: %v %v", expect, result, sc.Err())
+	}
+	for i, token := range result {
+		if token.Typeof != expect[i] {
+			t.Errorf("Test Semicolons failed. Expected: %s, Got: %v", expect[i], token)
+		}
+	}
+	// An inserted semicolon occupies the line break.
+	if s := result[12]; s.Value != "\n" || s.Line != 1 || s.Column != 25 || s.Size != 1 {
+		t.Errorf("Test Semicolons failed. Expected: line break at line:1 column:25, Got: %v", s)
+	}
+	// A line that begins with an infix or ternary operator continues the
+	// statement before it. One that begins with a minus sign does not.
+	continued := []struct {
+		text   string
+		expect []LexType
+	}{
+		{"c\n  ? x\n  : y", []LexType{Symbol, Question, Symbol, Colon, Symbol, EOF}},
+		{"if c\nthen x\n\nelse y", []LexType{If, Symbol, Then, Symbol, Else, Symbol, EOF}},
+		{"a\n&& b\nor c", []LexType{Symbol, And, Symbol, Or, Symbol, EOF}},
+		{"x\n+ 1\n-y", []LexType{Symbol, Add, Number, Semicolon, Sub, Symbol, EOF}},
+		{"x\norange", []LexType{Symbol, Semicolon, Symbol, EOF}},
+	}
+	for _, test := range continued {
+		sc := NewScanner(strings.NewReader(test.text))
+		sc.Semicolons = true
+		var types []LexType
+		for token := range sc.Tokens() {
+			types = append(types, token.Typeof)
+		}
+		if fmt.Sprint(types) != fmt.Sprint(test.expect) {
+			t.Errorf("Test Semicolons failed for %q. Expected: %v, Got: %v", test.text, test.expect, types)
+		}
+	}
+	// Without insertion, line breaks are space, and "2\nz" reads "2z".
+	result, _ = Scan("2\nz")
+	if len(result) != 4 || result[1].Typeof != ImpMul {
+		t.Errorf("Test Semicolons failed. Expected: implied multiplication, Got: %v", result)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	text := "f(x) := x ↦ x-1; (x -> 2x)(3) (x)(y) k(1)(2) (z)"
+	expect := []LexType{Symbol, OpenParen, Symbol, CloseParen, Define, Symbol, Arrow, Symbol, Sub, Number, Semicolon, OpenParen, Symbol, Arrow, Number, ImpMul, Symbol, CloseParen, OpenParen, Number, CloseParen, ImpMul, OpenParen, Symbol, CloseParen, ImpMul, OpenParen, Symbol, CloseParen, ImpMul, Symbol, OpenParen, Number, CloseParen, OpenParen, Number, CloseParen, ImpMul, OpenParen, Symbol, CloseParen, EOF}
+	result, err := Scan(text)
+	if err != nil || len(result) != len(expect) {
+		t.Fatalf("Test Functions failed. Expected: %v, Got: %v %v", expect, result, err)
+	}
+	for i, token := range result {
+		if token.Typeof != expect[i] {
+			t.Errorf("Test Functions failed. Expected: %s, Got: %v", expect[i], token)
+		}
+	}
+}
+
+func TestBrackets(t *testing.T) {
+	text := "[1 2x; a and b (c d)\n m[1, :]]"
+	expect := []LexType{OpenBracket, Number, Comma, Number, ImpMul, Symbol, Semicolon, Symbol, And, Symbol, Comma, OpenParen, Symbol, ImpMul, Symbol, CloseParen, Comma, Symbol, OpenBracket, Number, Comma, Colon, CloseBracket, CloseBracket, EOF}
+	result, err := Scan(text)
+	if err != nil || len(result) != len(expect) {
+		t.Fatalf("Test Brackets failed. Expected: %v, Got: %v %v", expect, result, err)
+	}
+	for i, token := range result {
+		if token.Typeof != expect[i] {
+			t.Errorf("Test Brackets failed. Expected: %s, Got: %v", expect[i], token)
+		}
+	}
+	// A separating comma occupies the whitespace it replaces.
+	if c := result[2]; c.Value != " " || c.Column != 3 || c.Size != 1 {
+		t.Errorf("Test Brackets failed. Expected: space at column 3, Got: %v", c)
+	}
+	if c := result[16]; c.Line != 1 || c.Column != 21 {
+		t.Errorf("Test Brackets failed. Expected: line break at line:1 column:21, Got: %v", c)
+	}
+}
+
+func TestStrings(t *testing.T) {
+	text := `name = "Ada" and f'(x) ≠ 'it\'s' ['a' "b\n"]`
+	expect := []LexType{Symbol, Equal, String, And, Symbol, Prime, OpenParen, Symbol, CloseParen, NotEqual, String, OpenBracket, String, Comma, String, CloseBracket, EOF}
+	result, err := Scan(text)
+	if err != nil || len(result) != len(expect) {
+		t.Fatalf("Test Strings failed. Expected: %v, Got: %v %v", expect, result, err)
+	}
+	for i, token := range result {
+		if token.Typeof != expect[i] {
+			t.Errorf("Test Strings failed. Expected: %s, Got: %v", expect[i], token)
+		}
+	}
+	if s := result[10]; s.Value != `'it\'s'` || s.Column != 26 || s.Size != 7 {
+		t.Errorf("Test Strings failed. Expected: 'it\\'s' at column 26, Got: %v", s)
+	}
+	unquotes := []struct {
+		text   string
+		expect string
+	}{
+		{`"Ada"`, "Ada"},
+		{`''`, ""},
+		{`'it\'s'`, "it's"},
+		{`"\"é\"\t\x41\u00e9\101"`, "\"é\"\tAéA"},
+		{`"\xff"`, "\xff"},
+	}
+	for _, test := range unquotes {
+		if result, err := Unquote(test.text); err != nil || result != test.expect {
+			t.Errorf("Test Strings failed for %s. Expected: %q, Got: %q %v", test.text, test.expect, result, err)
+		}
+	}
+	errs := []struct {
+		text     string
+		pos, end Position
+		value    string
+		msg      string
+	}{
+		{`1 + "Ada`, Position{Offset: 4, Line: 1, Column: 5}, Position{Offset: 8, Line: 1, Column: 9}, `"Ada`, "unterminated string"},
+		{"x = 'a\n'", Position{Offset: 4, Line: 1, Column: 5}, Position{Offset: 6, Line: 1, Column: 7}, "'a", "unterminated string"},
+		{`"a\qb"`, Position{Offset: 2, Line: 1, Column: 3}, Position{Offset: 4, Line: 1, Column: 5}, `\q`, "invalid escape sequence"},
+		{`"\x4"`, Position{Offset: 1, Line: 1, Column: 2}, Position{Offset: 4, Line: 1, Column: 5}, `\x4`, "invalid escape sequence"},
+		{`"\'"`, Position{Offset: 1, Line: 1, Column: 2}, Position{Offset: 3, Line: 1, Column: 4}, `\'`, "invalid escape sequence"},
+	}
+	for _, test := range errs {
+		_, err := Scan(test.text)
+		e, ok := err.(*Error)
+		if !ok || e.Pos != test.pos || e.End != test.end || e.Value != test.value || e.Msg != test.msg {
+			t.Errorf("Test Strings failed for %s. Expected: %s %q %v-%v, Got: %v", test.text, test.msg, test.value, test.pos, test.end, err)
+		}
+	}
+}
+
+func TestWidth(t *testing.T) {
+	text := "a ∧ b && ¬c != d ≤ e ↦ f × g"
+	expect := []int{1, 1, 1, 2, 1, 1, 2, 1, 1, 1, 1, 1, 1, 1, 0}
+	result, err := Scan(text)
+	if err != nil || len(result) != len(expect) {
+		t.Fatalf("Test Width failed. Expected: %d tokens, Got: %v %v", len(expect), result, err)
+	}
+	for i, token := range result {
+		if token.Width != expect[i] {
+			t.Errorf("Test Width failed for %v. Expected: %d, Got: %d", token, expect[i], token.Width)
+		}
+	}
+}
+
+func TestError(t *testing.T) {
+	text := "1 +\n ÷ $"
+	_, err := Scan(text)
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("Test Error failed. Expected: *Error, Got: %v", err)
+	}
+	pos := Position{Offset: 8, Line: 2, Column: 4}
+	end := Position{Offset: 9, Line: 2, Column: 5}
+	if e.Pos != pos || e.End != end || e.Value != "$" {
+		t.Errorf("Test Error failed. Expected: %q %v-%v, Got: %q %v-%v", "$", pos, end, e.Value, e.Pos, e.End)
+	}
+}
+
+// utility functions
+
+func mkEof(l, c, o int) Token {
+	return Token{
+		Typeof: EOF,
+		Line:   l,
+		Column: c,
+		Offset: o,
+	}
+}
+
+func compare(e []Token, r []Token, t *testing.T, n string) {
+	if len(e) != len(r) {
+		t.Errorf("Test %s failed. Token slices of unequal length.", n)
+	} else {
+		for i := range e {
+			exp := e[i]
+			got := r[i]
+			if exp != got {
+				t.Errorf("Test %s failed. Expected: %v, Got: %v", n, exp, got)
+			}
+		}
+	}
+}
+
+func TestScanner(t *testing.T) {
+	text := "2(x + 1) ×\n y"
+	expect, _ := Scan(text)
+	// Reading a byte at a time exercises the lookahead across reads.
+	sc := NewScanner(iotest.OneByteReader(strings.NewReader(text)))
+	var result []Token
+	for token := range sc.Tokens() {
+		result = append(result, token)
+	}
+	compare(expect, result, t, "Scanner")
+	if eof := sc.Next(); eof.Typeof != EOF {
+		t.Errorf("Test Scanner failed. Expected: <eof>, Got: %v", eof)
+	}
+}
+
+func TestScannerError(t *testing.T) {
+	sc := NewScanner(strings.NewReader("1 $ 2"))
+	for range sc.Tokens() {
+	}
+	var e *Error
+	if !errors.As(sc.Err(), &e) || e.Value != "$" {
+		t.Errorf("Test ScannerError failed. Expected: \"$\", Got: %v", sc.Err())
+	}
+
+	sc = NewScanner(strings.NewReader("1 $ 2 @"))
+	sc.Recover = true
+	count := 0
+	for range sc.Tokens() {
+		count += 1
+	}
+	if count != 5 || len(sc.Errors()) != 2 || sc.Err() != nil {
+		t.Errorf("Test ScannerError failed. Expected: 5 tokens, 2 errors, Got: %d tokens, %v %v", count, sc.Errors(), sc.Err())
+	}
+
+	failure := errors.New("failure")
+	sc = NewScanner(io.MultiReader(strings.NewReader("1 + 2"), iotest.ErrReader(failure)))
+	for range sc.Tokens() {
+	}
+	if !errors.Is(sc.Err(), failure) {
+		t.Errorf("Test ScannerError failed. Expected: %v, Got: %v", failure, sc.Err())
+	}
+}
+
+func TestLexTypeString(t *testing.T) {
+	tests := []struct {
+		typeof LexType
+		expect string
+	}{
+		{OpenParen, "("},
+		{NotEqual, "≠"},
+		{Mul, "*"},
+		{ImpMul, "ImpMul"},
+		{Number, "Number"},
+		{EOF, "EOF"},
+		{LexType(-1), "LexType(-1)"},
+		{LexType(len(LexNames)), fmt.Sprintf("LexType(%d)", len(LexNames))},
+	}
+	for _, test := range tests {
+		if result := test.typeof.String(); result != test.expect {
+			t.Errorf("Test LexTypeString failed. Expected: %s, Got: %s", test.expect, result)
+		}
+	}
+	for i, name := range LexNames {
+		if name == "" {
+			t.Errorf("Test LexTypeString failed. LexType(%d) has no name", i)
+		}
+	}
+}
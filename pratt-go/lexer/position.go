@@ -0,0 +1,228 @@
+package lexer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Unit in which a column is counted. Editors differ: most count
+// characters, terminals count bytes, and the Language Server Protocol
+// counts UTF-16 code units.
+type Unit int
+
+const (
+	Runes Unit = iota // Unicode code points. Matches "Token.Column".
+	Bytes             // UTF-8 bytes.
+	UTF16             // UTF-16 code units. Runes beyond U+FFFF count twice.
+)
+
+// Pos is a compact position within a FileSet: the file's base plus
+// a byte offset within the file. The zero value, "NoPos", locates
+// nothing.
+type Pos int
+
+const NoPos Pos = 0
+
+// Reports whether "p" locates something.
+func (p Pos) IsValid() bool {
+	return p != NoPos
+}
+
+// A File records the source text of a single file and the offset
+// of each of its lines, so that byte offsets convert to lines and
+// columns. Safe for concurrent use.
+type File struct {
+	name  string // File name, as given to "NewFile" or "AddFile".
+	base  int    // Pos of the file's first byte within its FileSet.
+	src   string // Source text.
+	lines []int  // Byte offset of each line's first character.
+}
+
+// Inputs a file name and source text, outputs a File standing alone,
+// outside any FileSet. Its base is 1.
+func NewFile(name, src string) *File {
+	lines := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == newline {
+			lines = append(lines, i+1)
+		}
+	}
+	return &File{
+		name:  name,
+		base:  1,
+		src:   src,
+		lines: lines,
+	}
+}
+
+// Outputs the file name.
+func (f *File) Name() string { return f.name }
+
+// Outputs the Pos of the file's first byte.
+func (f *File) Base() int { return f.base }
+
+// Outputs the size of the file in bytes.
+func (f *File) Size() int { return len(f.src) }
+
+// Outputs the source text.
+func (f *File) Source() string { return f.src }
+
+// Outputs the number of lines in the file.
+func (f *File) LineCount() int { return len(f.lines) }
+
+// Outputs the byte offset of the first character of "line", counting
+// from 1. Panics if the line does not exist.
+func (f *File) LineStart(line int) int {
+	if line < 1 || line > len(f.lines) {
+		panic(fmt.Sprintf("invalid line number %d (should be >= 1 and <= %d)", line, len(f.lines)))
+	}
+	return f.lines[line-1]
+}
+
+// Inputs a byte offset, outputs the matching Pos. Panics if the offset
+// lies outside the file. The file's size is a valid offset: it locates EOF.
+func (f *File) Pos(offset int) Pos {
+	if offset < 0 || offset > len(f.src) {
+		panic(fmt.Sprintf("invalid offset %d (should be >= 0 and <= %d)", offset, len(f.src)))
+	}
+	return Pos(f.base + offset)
+}
+
+// Inputs a Pos, outputs its byte offset within the file. Panics if "p"
+// lies outside the file.
+func (f *File) Offset(p Pos) int {
+	offset := int(p) - f.base
+	if offset < 0 || offset > len(f.src) {
+		panic(fmt.Sprintf("invalid Pos value %d (should be in [%d, %d])", p, f.base, f.base+len(f.src)))
+	}
+	return offset
+}
+
+// Inputs a byte offset, outputs its full position. Columns count runes,
+// as in tokens. Offsets outside the file are clamped to it.
+func (f *File) Position(offset int) Position {
+	offset = max(0, min(offset, len(f.src)))
+	line := f.line(offset)
+	return Position{
+		Filename: f.name,
+		Offset:   offset,
+		Line:     line,
+		Column:   f.column(line, offset, Runes),
+	}
+}
+
+// Inputs a byte offset, outputs its column counted in "unit", starting
+// at 1. Offsets outside the file are clamped to it.
+func (f *File) Column(offset int, unit Unit) int {
+	offset = max(0, min(offset, len(f.src)))
+	return f.column(f.line(offset), offset, unit)
+}
+
+// Inputs a line and a column counted in "unit", outputs the byte offset
+// they locate. Columns past the end of a line stop at its newline.
+func (f *File) LineColumn(line, column int, unit Unit) int {
+	if line < 1 {
+		return 0
+	}
+	if line > len(f.lines) {
+		return len(f.src)
+	}
+	offset := f.lines[line-1]
+	for c := 1; c < column && offset < len(f.src) && f.src[offset] != newline; {
+		r, w := utf8.DecodeRuneInString(f.src[offset:])
+		offset += w
+		c += width(r, w, unit)
+	}
+	return offset
+}
+
+// Outputs the line containing "offset".
+func (f *File) line(offset int) int {
+	return sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset })
+}
+
+// Counts the columns in "unit" from the start of "line" to "offset".
+func (f *File) column(line, offset int, unit Unit) int {
+	start := f.lines[line-1]
+	if unit == Bytes {
+		return offset - start + 1
+	}
+	column := 1
+	for _, r := range f.src[start:offset] {
+		column += width(r, utf8.RuneLen(r), unit)
+	}
+	return column
+}
+
+// Outputs the width of rune "r", "w" bytes long in UTF-8, in "unit".
+func width(r rune, w int, unit Unit) int {
+	switch unit {
+	case Bytes:
+		return w
+	case UTF16:
+		if n := utf16.RuneLen(r); n > 0 {
+			return n
+		}
+		return 1 // Invalid runes encode as U+FFFD.
+	default:
+		return 1
+	}
+}
+
+// A FileSet holds a set of source files, each occupying its own range
+// of Pos values, so that a single Pos identifies both a file and an
+// offset within it. Safe for concurrent use.
+type FileSet struct {
+	mu    sync.RWMutex
+	base  int     // Base of the next file added.
+	files []*File // Files, in order of increasing base.
+}
+
+// Outputs an empty FileSet.
+func NewFileSet() *FileSet {
+	return &FileSet{base: 1}
+}
+
+// Inputs a file name and source text, outputs a File added to the set.
+// Each file's range of Pos values includes one value past its last byte,
+// for EOF.
+func (s *FileSet) AddFile(name, src string) *File {
+	f := NewFile(name, src)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f.base = s.base
+	s.base += len(src) + 1
+	s.files = append(s.files, f)
+	return f
+}
+
+// Outputs the file containing "p", or nil if there is none.
+func (s *FileSet) File(p Pos) *File {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := sort.Search(len(s.files), func(i int) bool { return s.files[i].base > int(p) }) - 1
+	if i < 0 || int(p) > s.files[i].base+s.files[i].Size() {
+		return nil
+	}
+	return s.files[i]
+}
+
+// Outputs the full position of "p", or the zero Position if no file
+// in the set contains it.
+func (s *FileSet) Position(p Pos) Position {
+	f := s.File(p)
+	if f == nil {
+		return Position{}
+	}
+	return f.Position(f.Offset(p))
+}
+
+// Outputs the set's files in the order they were added.
+func (s *FileSet) Files() []*File {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*File(nil), s.files...)
+}
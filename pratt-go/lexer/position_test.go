@@ -0,0 +1,87 @@
+package lexer
+
+import "testing"
+
+func TestFilePosition(t *testing.T) {
+	f := NewFile("test.txt", "1 +\n× 2")
+	tests := []struct {
+		offset int
+		expect Position
+	}{
+		{0, Position{Filename: "test.txt", Offset: 0, Line: 1, Column: 1}},
+		{3, Position{Filename: "test.txt", Offset: 3, Line: 1, Column: 4}},
+		{4, Position{Filename: "test.txt", Offset: 4, Line: 2, Column: 1}},
+		{7, Position{Filename: "test.txt", Offset: 7, Line: 2, Column: 3}},
+		{8, Position{Filename: "test.txt", Offset: 8, Line: 2, Column: 4}},
+		{99, Position{Filename: "test.txt", Offset: 8, Line: 2, Column: 4}},
+	}
+	for _, test := range tests {
+		result := f.Position(test.offset)
+		if result != test.expect {
+			t.Errorf("Test FilePosition failed for %d. Expected: %v, Got: %v", test.offset, test.expect, result)
+		}
+	}
+}
+
+func TestFileColumn(t *testing.T) {
+	// "é" is 2 bytes and 1 UTF-16 unit. "𝑥" is 4 bytes and 2 UTF-16 units.
+	f := NewFile("", "é + 𝑥 + y")
+	offset := len("é + 𝑥 + ")
+	tests := []struct {
+		unit   Unit
+		expect int
+	}{
+		{Runes, 9},
+		{Bytes, 13},
+		{UTF16, 10},
+	}
+	for _, test := range tests {
+		result := f.Column(offset, test.unit)
+		if result != test.expect {
+			t.Errorf("Test FileColumn failed for unit %d. Expected: %d, Got: %d", test.unit, test.expect, result)
+		}
+		back := f.LineColumn(1, result, test.unit)
+		if back != offset {
+			t.Errorf("Test FileColumn failed for unit %d. Expected: %d, Got: %d", test.unit, offset, back)
+		}
+	}
+}
+
+func TestFileSet(t *testing.T) {
+	fset := NewFileSet()
+	a := fset.AddFile("a.txt", "1 + 2")
+	b := fset.AddFile("b.txt", "x\ny")
+	if fset.File(NoPos) != nil {
+		t.Errorf("Test FileSet failed. Expected: nil file for NoPos")
+	}
+	tests := []struct {
+		pos    Pos
+		expect Position
+	}{
+		{a.Pos(4), Position{Filename: "a.txt", Offset: 4, Line: 1, Column: 5}},
+		{a.Pos(5), Position{Filename: "a.txt", Offset: 5, Line: 1, Column: 6}},
+		{b.Pos(0), Position{Filename: "b.txt", Offset: 0, Line: 1, Column: 1}},
+		{b.Pos(2), Position{Filename: "b.txt", Offset: 2, Line: 2, Column: 1}},
+		{Pos(100), Position{}},
+	}
+	for _, test := range tests {
+		result := fset.Position(test.pos)
+		if result != test.expect {
+			t.Errorf("Test FileSet failed for %d. Expected: %v, Got: %v", test.pos, test.expect, result)
+		}
+	}
+}
+
+func TestTokenOffset(t *testing.T) {
+	text := "7 × x\n÷ 2"
+	expect := []int{0, 2, 5, 7, 10, 11}
+	result, _ := Scan(text)
+	if len(result) != len(expect) {
+		t.Fatalf("Test TokenOffset failed. Expected: %d tokens, Got: %v", len(expect), result)
+	}
+	for i, token := range result {
+		if token.Offset != expect[i] {
+			t.Errorf("Test TokenOffset failed for %v. Expected: %d, Got: %d", token, expect[i], token.Offset)
+		}
+	}
+}
@@ -1,21 +1,49 @@
 package parser
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
 
-// The interface that all AST components must satisfy.
-type Node interface{ ast() }
+// The interface that all AST components must satisfy. "Pos" locates
+// the first character of the expression a node spans, and "End" the
+// character immediately after its last. Positions count lines and
+// columns from 1 and byte offsets from 0.
+type Node interface {
+	Pos() Position
+	End() Position
+	ast()
+}
 
-// An empty string creates an empty Node.
+// An empty string creates an empty Node. Spans no text,
+// so its positions are zero.
 type Empty struct{}
 
 func (e Empty) String() string {
 	return "Empty{}"
 }
 
-// Number parsed as 64-bit floating point.
+// Stands in for an expression that failed to parse.
+// Produced only when recovering from errors. Spans the
+// text skipped in recovery, up to "To".
+type Bad struct {
+	Line, Column int
+	Offset       int
+	To           Position
+}
+
+func (b Bad) String() string {
+	return "Bad{}"
+}
+
+// Number parsed as 64-bit floating point. Keeps its literal text
+// for evaluation at higher precision.
 type Number struct {
 	Value        float64
+	Raw          string
 	Line, Column int
+	Offset       int
 }
 
 func (n Number) String() string {
@@ -28,6 +56,7 @@ func (n Number) String() string {
 type Symbol struct {
 	Value        string
 	Line, Column int
+	Offset       int
 }
 
 func (s Symbol) String() string {
@@ -35,11 +64,38 @@ func (s Symbol) String() string {
 	return fmt.Sprintf(msg, s.Value)
 }
 
-// Operation with one operand.
+// Boolean literal, "true" or "false".
+type Boolean struct {
+	Value        bool
+	Line, Column int
+	Offset       int
+}
+
+func (b Boolean) String() string {
+	msg := "Boolean{ Value: %t }"
+	return fmt.Sprintf(msg, b.Value)
+}
+
+// String literal, quoted by '"' or "'". Keeps its literal text, quotes
+// and escapes included, for printing.
+type String struct {
+	Value        string
+	Raw          string
+	Line, Column int
+	Offset       int
+}
+
+func (s String) String() string {
+	msg := "String{ Value: %q }"
+	return fmt.Sprintf(msg, s.Value)
+}
+
+// Operation with one operand. Positioned at its operator.
 type Unary struct {
 	Op           string
 	X            Node
 	Line, Column int
+	Offset       int
 }
 
 func (u Unary) String() string {
@@ -47,11 +103,29 @@ func (u Unary) String() string {
 	return fmt.Sprintf(msg, u.Op, u.X)
 }
 
-// Operation with two operands.
+// Operation written after its one operand, as in "3!", "50%", or "f'".
+// Positioned at its operator. "Size" is the length of the operator in
+// source, in bytes, which differs from "Op" for alternate spellings
+// such as "′".
+type Postfix struct {
+	Op           string
+	X            Node
+	Line, Column int
+	Offset       int
+	Size         int
+}
+
+func (p Postfix) String() string {
+	msg := "Postfix{ Op: %q, X: %s }"
+	return fmt.Sprintf(msg, p.Op, p.X)
+}
+
+// Operation with two operands. Positioned at its operator.
 type Binary struct {
 	Op           string
 	X, Y         Node
 	Line, Column int
+	Offset       int
 }
 
 func (b Binary) String() string {
@@ -59,10 +133,14 @@ func (b Binary) String() string {
 	return fmt.Sprintf(msg, b.Op, b.X, b.Y)
 }
 
-// Like Binary but without positional information.
+// Like Binary but with an operator implied by juxtaposition, as in "2x".
+// Positioned immediately after its left operand, where the operator
+// would be written.
 type ImpliedBinary struct {
-	Op   string
-	X, Y Node
+	Op           string
+	X, Y         Node
+	Line, Column int
+	Offset       int
 }
 
 func (i ImpliedBinary) String() string {
@@ -70,11 +148,107 @@ func (i ImpliedBinary) String() string {
 	return fmt.Sprintf(msg, i.Op, i.X, i.Y)
 }
 
-// Function call.
+// Chain of non-associative operations sharing operands, as in
+// "0 ≤ x < 10", which reads "0 ≤ x ∧ x < 10". Operator "Ops[i]" joins
+// "Operands[i]" and "Operands[i+1]". Positioned at its first operator.
+type Comparison struct {
+	Ops          []string
+	Operands     []Node // One more than "Ops".
+	Line, Column int
+	Offset       int
+}
+
+func (c Comparison) String() string {
+	msg := "Comparison{ Ops: %q, Operands: %v }"
+	return fmt.Sprintf(msg, c.Ops, c.Operands)
+}
+
+// Conditional expression, "c ? x : y" or "if c then x else y", which
+// reads "Then" if "Cond" holds and "Else" otherwise. Positioned at "?"
+// or "if". "ThenPos" locates "then", or is zero for "?". "ElsePos"
+// locates ":" or "else".
+type Conditional struct {
+	Cond, Then, Else Node
+	Keyword          bool // If true, written with "if", "then", and "else".
+	Line, Column     int
+	Offset           int
+	ThenPos, ElsePos Position
+}
+
+func (c Conditional) String() string {
+	msg := "Conditional{ Cond: %s, Then: %s, Else: %s }"
+	return fmt.Sprintf(msg, c.Cond, c.Then, c.Else)
+}
+
+// Binds "Name" to the value of "Value" for the statements that follow,
+// as in "r = 3" or "let r = 3". Unlike the "=" operator, which tests
+// equality, occurs only as a statement of a Program. Positioned at "=".
+// "Let" locates the keyword "let", or is zero if it is absent.
+type Assign struct {
+	Name         Symbol
+	Value        Node
+	Line, Column int
+	Offset       int
+	Let          Position
+}
+
+func (a Assign) String() string {
+	msg := "Assign{ Name: %s, Value: %s }"
+	return fmt.Sprintf(msg, a.Name, a.Value)
+}
+
+// Sequence of statements separated by semicolons or line breaks, as
+// output by "ParseProgram". Its value is that of its last statement.
+type Program struct {
+	Stmts []Node
+}
+
+func (p Program) String() string {
+	msg := "Program{ Stmts: %v }"
+	return fmt.Sprintf(msg, p.Stmts)
+}
+
+// Anonymous function, as in "(x, y) -> x + y" or "x ↦ 2x". Positioned
+// at its arrow. "Lparen" and "Rparen" locate the parentheses around its
+// parameters, or are zero if a lone parameter is written without them.
+type Lambda struct {
+	Params         []Symbol
+	Body           Node
+	Line, Column   int
+	Offset         int
+	Lparen, Rparen Position
+}
+
+func (l Lambda) String() string {
+	msg := "Lambda{ Params: %v, Body: %s }"
+	return fmt.Sprintf(msg, l.Params, l.Body)
+}
+
+// Function definition, as in "f(x, y) := x^2 + y", which binds "Name"
+// to a function of "Params". Positioned at ":=". "Lparen" and "Rparen"
+// locate the parentheses around its parameters.
+type FuncDef struct {
+	Name           Symbol
+	Params         []Symbol
+	Body           Node
+	Line, Column   int
+	Offset         int
+	Lparen, Rparen Position
+}
+
+func (f FuncDef) String() string {
+	msg := "FuncDef{ Name: %s, Params: %v, Body: %s }"
+	return fmt.Sprintf(msg, f.Name, f.Params, f.Body)
+}
+
+// Function call. Positioned at its opening parenthesis. "Rparen"
+// locates the closing parenthesis, or is zero if it is missing.
 type Call struct {
 	Callee       Node
 	Args         []Node
 	Line, Column int
+	Offset       int
+	Rparen       Position
 }
 
 func (c Call) String() string {
@@ -82,13 +256,279 @@ func (c Call) String() string {
 	return fmt.Sprintf(msg, c.Callee, c.Args)
 }
 
+// List of elements separated by commas, as in "[1, 2, 3]". "Rbrack"
+// is zero if the closing bracket is missing.
+type List struct {
+	Elems          []Node
+	Lbrack, Rbrack Position
+}
+
+func (l List) String() string {
+	msg := "List{ Elems: %v }"
+	return fmt.Sprintf(msg, l.Elems)
+}
+
+// Matrix of rows separated by semicolons, whose elements are separated
+// by commas or whitespace, as in "[1 2; 3 4]". "Rbrack" is zero if the
+// closing bracket is missing.
+type Matrix struct {
+	Rows           [][]Node
+	Lbrack, Rbrack Position
+}
+
+func (m Matrix) String() string {
+	msg := "Matrix{ Rows: %v }"
+	return fmt.Sprintf(msg, m.Rows)
+}
+
+// Index into a list or matrix, as in "v[2]" or "m[1, :]". A nil index
+// stands for ":", which selects every element along its dimension.
+// Positioned at its opening bracket. "Rbrack" locates the closing
+// bracket, or is zero if it is missing.
+type Index struct {
+	X            Node
+	Indices      []Node
+	Line, Column int
+	Offset       int
+	Rbrack       Position
+}
+
+func (i Index) String() string {
+	msg := "Index{ X: %s, Indices: %v }"
+	return fmt.Sprintf(msg, i.X, i.Indices)
+}
+
+// Parenthesized expression. "Rparen" is zero if the closing
+// parenthesis is missing.
+type Paren struct {
+	X              Node
+	Lparen, Rparen Position
+}
+
+func (p Paren) String() string {
+	msg := "Paren{ X: %s }"
+	return fmt.Sprintf(msg, p.X)
+}
+
+// Positions of each node type.
+
+func (e Empty) Pos() Position { return Position{} }
+func (e Empty) End() Position { return Position{} }
+
+func (b Bad) Pos() Position { return at(b.Offset, b.Line, b.Column) }
+func (b Bad) End() Position { return b.To }
+
+func (n Number) Pos() Position { return at(n.Offset, n.Line, n.Column) }
+func (n Number) End() Position { return advance(n.Pos(), n.Raw) }
+
+func (s Symbol) Pos() Position { return at(s.Offset, s.Line, s.Column) }
+func (s Symbol) End() Position { return advance(s.Pos(), s.Value) }
+
+func (b Boolean) Pos() Position { return at(b.Offset, b.Line, b.Column) }
+func (b Boolean) End() Position { return advance(b.Pos(), strconv.FormatBool(b.Value)) }
+
+func (s String) Pos() Position { return at(s.Offset, s.Line, s.Column) }
+func (s String) End() Position { return advance(s.Pos(), s.Raw) }
+
+func (u Unary) Pos() Position { return at(u.Offset, u.Line, u.Column) }
+func (u Unary) End() Position { return end(u.X, advance(u.Pos(), u.Op)) }
+
+func (p Postfix) Pos() Position { return begin(p.X, at(p.Offset, p.Line, p.Column)) }
+func (p Postfix) End() Position {
+	pos := advance(at(p.Offset, p.Line, p.Column), p.Op)
+	if p.Size > 0 {
+		// Zero in nodes built by hand, not by the parser.
+		pos.Offset = p.Offset + p.Size
+	}
+	return pos
+}
+
+func (b Binary) Pos() Position { return begin(b.X, at(b.Offset, b.Line, b.Column)) }
+func (b Binary) End() Position { return end(b.Y, advance(at(b.Offset, b.Line, b.Column), b.Op)) }
+
+func (i ImpliedBinary) Pos() Position { return begin(i.X, at(i.Offset, i.Line, i.Column)) }
+func (i ImpliedBinary) End() Position { return end(i.Y, at(i.Offset, i.Line, i.Column)) }
+
+func (c Comparison) Pos() Position {
+	op := at(c.Offset, c.Line, c.Column)
+	if len(c.Operands) == 0 {
+		return op
+	}
+	return begin(c.Operands[0], op)
+}
+func (c Comparison) End() Position {
+	op := at(c.Offset, c.Line, c.Column)
+	if len(c.Operands) == 0 {
+		return op
+	}
+	return end(c.Operands[len(c.Operands)-1], op)
+}
+
+func (c Conditional) Pos() Position {
+	op := at(c.Offset, c.Line, c.Column)
+	if c.Keyword {
+		return op
+	}
+	return begin(c.Cond, op)
+}
+func (c Conditional) End() Position {
+	if c.Keyword {
+		return end(c.Else, advance(c.ElsePos, "else"))
+	}
+	return end(c.Else, advance(c.ElsePos, ":"))
+}
+
+func (a Assign) Pos() Position {
+	if a.Let.Line > 0 {
+		return a.Let
+	}
+	return begin(a.Name, at(a.Offset, a.Line, a.Column))
+}
+func (a Assign) End() Position { return end(a.Value, advance(at(a.Offset, a.Line, a.Column), "=")) }
+
+func (p Program) Pos() Position {
+	if len(p.Stmts) == 0 {
+		return Position{}
+	}
+	return p.Stmts[0].Pos()
+}
+func (p Program) End() Position {
+	if len(p.Stmts) == 0 {
+		return Position{}
+	}
+	return p.Stmts[len(p.Stmts)-1].End()
+}
+
+func (l Lambda) Pos() Position {
+	if l.Lparen.Line > 0 {
+		return l.Lparen
+	}
+	arrow := at(l.Offset, l.Line, l.Column)
+	if len(l.Params) == 0 {
+		return arrow
+	}
+	return begin(l.Params[0], arrow)
+}
+func (l Lambda) End() Position { return end(l.Body, advance(at(l.Offset, l.Line, l.Column), "->")) }
+
+func (f FuncDef) Pos() Position { return begin(f.Name, at(f.Offset, f.Line, f.Column)) }
+func (f FuncDef) End() Position { return end(f.Body, advance(at(f.Offset, f.Line, f.Column), ":=")) }
+
+func (c Call) Pos() Position { return begin(c.Callee, at(c.Offset, c.Line, c.Column)) }
+func (c Call) End() Position {
+	if c.Rparen.Line > 0 {
+		return advance(c.Rparen, ")")
+	}
+	lparen := advance(at(c.Offset, c.Line, c.Column), "(")
+	if len(c.Args) > 0 {
+		return end(c.Args[len(c.Args)-1], lparen)
+	}
+	return lparen
+}
+
+func (l List) Pos() Position { return l.Lbrack }
+func (l List) End() Position {
+	if l.Rbrack.Line > 0 {
+		return advance(l.Rbrack, "]")
+	}
+	lbrack := advance(l.Lbrack, "[")
+	if len(l.Elems) > 0 {
+		return end(l.Elems[len(l.Elems)-1], lbrack)
+	}
+	return lbrack
+}
+
+func (m Matrix) Pos() Position { return m.Lbrack }
+func (m Matrix) End() Position {
+	if m.Rbrack.Line > 0 {
+		return advance(m.Rbrack, "]")
+	}
+	lbrack := advance(m.Lbrack, "[")
+	if n := len(m.Rows); n > 0 && len(m.Rows[n-1]) > 0 {
+		row := m.Rows[n-1]
+		return end(row[len(row)-1], lbrack)
+	}
+	return lbrack
+}
+
+func (i Index) Pos() Position { return begin(i.X, at(i.Offset, i.Line, i.Column)) }
+func (i Index) End() Position {
+	if i.Rbrack.Line > 0 {
+		return advance(i.Rbrack, "]")
+	}
+	lbrack := advance(at(i.Offset, i.Line, i.Column), "[")
+	if len(i.Indices) > 0 {
+		return end(i.Indices[len(i.Indices)-1], lbrack)
+	}
+	return lbrack
+}
+
+func (p Paren) Pos() Position { return p.Lparen }
+func (p Paren) End() Position {
+	if p.Rparen.Line > 0 {
+		return advance(p.Rparen, ")")
+	}
+	return end(p.X, advance(p.Lparen, "("))
+}
+
+func at(offset, line, column int) Position {
+	return Position{
+		Offset: offset,
+		Line:   line,
+		Column: column,
+	}
+}
+
+// Outputs the position following text "s", which contains no newlines.
+func advance(p Position, s string) Position {
+	p.Offset += len(s)
+	p.Column += utf8.RuneCountInString(s)
+	return p
+}
+
+// Outputs the start of "n", or "otherwise" if "n" is nil or spans no text.
+func begin(n Node, otherwise Position) Position {
+	if n == nil {
+		return otherwise
+	}
+	if p := n.Pos(); p.Line > 0 {
+		return p
+	}
+	return otherwise
+}
+
+// Outputs the end of "n", or "otherwise" if "n" is nil or spans no text.
+func end(n Node, otherwise Position) Position {
+	if n == nil {
+		return otherwise
+	}
+	if e := n.End(); e.Line > 0 {
+		return e
+	}
+	return otherwise
+}
+
 // ast() is an empty method. It exists solely to group
 // selected types under the Node interface.
 
 func (e Empty) ast()         {}
+func (b Bad) ast()           {}
 func (n Number) ast()        {}
+func (s String) ast()        {}
 func (s Symbol) ast()        {}
+func (b Boolean) ast()       {}
 func (u Unary) ast()         {}
+func (p Postfix) ast()       {}
 func (b Binary) ast()        {}
 func (i ImpliedBinary) ast() {}
+func (c Comparison) ast()    {}
+func (c Conditional) ast()   {}
+func (a Assign) ast()        {}
+func (p Program) ast()       {}
+func (l Lambda) ast()        {}
+func (f FuncDef) ast()       {}
 func (c Call) ast()          {}
+func (l List) ast()          {}
+func (m Matrix) ast()        {}
+func (i Index) ast()         {}
+func (p Paren) ast()         {}
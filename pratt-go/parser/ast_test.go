@@ -0,0 +1,70 @@
+package parser
+
+import "testing"
+
+func TestSpan(t *testing.T) {
+	tests := []struct {
+		text   string
+		expect string // Text spanned by the root node.
+	}{
+		{"  42  ", "42"},
+		{"pi", "pi"},
+		{"-x", "-x"},
+		{"1 + 2 * 3", "1 + 2 * 3"},
+		{"7x", "7x"},
+		{"2(x + 1)", "2(x + 1)"},
+		{"sum(1, 2) ", "sum(1, 2)"},
+		{"random()", "random()"},
+		{" ((1 + 2)) ", "((1 + 2))"},
+		{"a × b ÷ c", "a × b ÷ c"},
+		{"1 +\n 2", "1 +\n 2"},
+		{"f′ ", "f′"},
+		{"f′′(x) ", "f′′(x)"},
+		{"3!% ", "3!%"},
+	}
+	for _, test := range tests {
+		node, err := Parse(test.text)
+		if err != nil {
+			t.Fatalf("TestSpan failed for %q. Got: %s", test.text, err)
+		}
+		result := test.text[node.Pos().Offset:node.End().Offset]
+		if result != test.expect {
+			t.Errorf("TestSpan failed for %q. Expected: %q, Got: %q", test.text, test.expect, result)
+		}
+	}
+}
+
+func TestSpanPosition(t *testing.T) {
+	text := "1 +\nf(x × 2)"
+	node, err := Parse(text)
+	if err != nil {
+		t.Fatalf("TestSpanPosition failed. Got: %s", err)
+	}
+	call := node.(Binary).Y.(Call)
+	tests := []struct {
+		result Position
+		expect Position
+	}{
+		{node.Pos(), Position{Offset: 0, Line: 1, Column: 1}},
+		{node.End(), Position{Offset: 13, Line: 2, Column: 9}},
+		{call.Pos(), Position{Offset: 4, Line: 2, Column: 1}},
+		{call.Args[0].End(), Position{Offset: 12, Line: 2, Column: 8}},
+		{call.Rparen, Position{Offset: 12, Line: 2, Column: 8}},
+	}
+	for _, test := range tests {
+		if test.result != test.expect {
+			t.Errorf("TestSpanPosition failed. Expected: %s, Got: %s", test.expect, test.result)
+		}
+	}
+}
+
+func TestSpanBad(t *testing.T) {
+	text := "1 + * 7 + 2"
+	node, _ := ParseAll(text)
+	bad := node.(Binary).X.(Binary).Y.(Bad)
+	expect := "* 7"
+	result := text[bad.Pos().Offset:bad.End().Offset]
+	if result != expect {
+		t.Errorf("TestSpanBad failed. Expected: %q, Got: %q", expect, result)
+	}
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"sync"
+	"testing"
+)
+
+// Parses each of "texts" in turn, so that concurrent results have
+// something to match.
+func parseAll(texts []string) ([]Node, []error) {
+	nodes := make([]Node, len(texts))
+	errs := make([]error, len(texts))
+	for i, text := range texts {
+		nodes[i], errs[i] = Parse(text)
+	}
+	return nodes, errs
+}
+
+// Run with "go test -race" to detect shared parser state. Neighbouring
+// goroutines parse different fixtures at the same time, so they disagree
+// on both tokens and token positions.
+func TestConcurrentParse(t *testing.T) {
+	expects, _ := parseAll(fixtures)
+	const workers = 64
+	const rounds = 200
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				i := (w + r) % len(fixtures)
+				text, expect := fixtures[i], expects[i]
+				result, err := Parse(text)
+				if err != nil {
+					t.Errorf("TestConcurrentParse failed for %q. Expected: %s, Got: %s", text, expect, err)
+					return
+				}
+				if !Equal(expect, result) {
+					t.Errorf("TestConcurrentParse failed for %q. Expected: %s, Got: %s", text, expect, result)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func TestConcurrentErrors(t *testing.T) {
+	tests := []string{"1 +", "sin(7", "1 + * 7", "(1 + 2", "1 + 2 3"}
+	_, errs := parseAll(tests)
+	expects, _ := parseAll(fixtures)
+	var wg sync.WaitGroup
+	for w := 0; w < 32; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < 100; r++ {
+				i := (w + r) % len(tests)
+				text, expect := tests[i], errs[i]
+				if result, err := Parse(text); err == nil || err.Error() != expect.Error() {
+					t.Errorf("TestConcurrentErrors failed for %q. Expected: %s, Got: %v %v", text, expect, result, err)
+					return
+				}
+				j := (w + r) % len(fixtures)
+				result, err := Parse(fixtures[j])
+				if err != nil || !Equal(expects[j], result) {
+					t.Errorf("TestConcurrentErrors failed for %q. Expected: %s, Got: %v %v", fixtures[j], expects[j], result, err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
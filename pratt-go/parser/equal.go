@@ -0,0 +1,368 @@
+package parser
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+)
+
+// Configures "Equal".
+type EqualOption func(*equality)
+
+type equality struct {
+	positions bool // If true, lines and columns must match.
+}
+
+// Compares nodes by structure alone, ignoring lines and columns.
+func IgnorePositions() EqualOption {
+	return func(e *equality) { e.positions = false }
+}
+
+// Reports whether nodes "n" and "m" are structurally equal: of the same
+// type, with the same operators, values, and literal text, and with
+// equal children. Positions must match unless "IgnorePositions" is given.
+// Positions compare by line and column alone, byte offsets following
+// from them within the same source. Nil nodes equal only each other.
+func Equal(n, m Node, opts ...EqualOption) bool {
+	e := equality{positions: true}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	return e.equal(n, m)
+}
+
+func (e equality) at(l1, c1, l2, c2 int) bool {
+	return !e.positions || (l1 == l2 && c1 == c2)
+}
+
+func (e equality) same(p, q Position) bool {
+	return e.at(p.Line, p.Column, q.Line, q.Column)
+}
+
+func (e equality) equal(n, m Node) bool {
+	switch n := n.(type) {
+	case nil:
+		return m == nil
+	case Empty:
+		_, ok := m.(Empty)
+		return ok
+	case Bad:
+		m, ok := m.(Bad)
+		return ok && e.at(n.Line, n.Column, m.Line, m.Column) && e.same(n.To, m.To)
+	case Number:
+		m, ok := m.(Number)
+		return ok && n.Value == m.Value && n.Raw == m.Raw &&
+			e.at(n.Line, n.Column, m.Line, m.Column)
+	case Symbol:
+		m, ok := m.(Symbol)
+		return ok && n.Value == m.Value &&
+			e.at(n.Line, n.Column, m.Line, m.Column)
+	case Boolean:
+		m, ok := m.(Boolean)
+		return ok && n.Value == m.Value &&
+			e.at(n.Line, n.Column, m.Line, m.Column)
+	case String:
+		m, ok := m.(String)
+		return ok && n.Value == m.Value && n.Raw == m.Raw &&
+			e.at(n.Line, n.Column, m.Line, m.Column)
+	case Unary:
+		m, ok := m.(Unary)
+		return ok && n.Op == m.Op && e.equal(n.X, m.X) &&
+			e.at(n.Line, n.Column, m.Line, m.Column)
+	case Postfix:
+		m, ok := m.(Postfix)
+		return ok && n.Op == m.Op && e.equal(n.X, m.X) &&
+			e.at(n.Line, n.Column, m.Line, m.Column)
+	case Binary:
+		m, ok := m.(Binary)
+		return ok && n.Op == m.Op && e.equal(n.X, m.X) && e.equal(n.Y, m.Y) &&
+			e.at(n.Line, n.Column, m.Line, m.Column)
+	case ImpliedBinary:
+		m, ok := m.(ImpliedBinary)
+		return ok && n.Op == m.Op && e.equal(n.X, m.X) && e.equal(n.Y, m.Y) &&
+			e.at(n.Line, n.Column, m.Line, m.Column)
+	case Comparison:
+		m, ok := m.(Comparison)
+		if !ok || len(n.Ops) != len(m.Ops) || len(n.Operands) != len(m.Operands) {
+			return false
+		}
+		for i := range n.Ops {
+			if n.Ops[i] != m.Ops[i] {
+				return false
+			}
+		}
+		for i := range n.Operands {
+			if !e.equal(n.Operands[i], m.Operands[i]) {
+				return false
+			}
+		}
+		return e.at(n.Line, n.Column, m.Line, m.Column)
+	case Conditional:
+		m, ok := m.(Conditional)
+		return ok && n.Keyword == m.Keyword &&
+			e.equal(n.Cond, m.Cond) && e.equal(n.Then, m.Then) && e.equal(n.Else, m.Else) &&
+			e.at(n.Line, n.Column, m.Line, m.Column) &&
+			e.same(n.ThenPos, m.ThenPos) && e.same(n.ElsePos, m.ElsePos)
+	case Assign:
+		m, ok := m.(Assign)
+		return ok && e.equal(n.Name, m.Name) && e.equal(n.Value, m.Value) &&
+			e.at(n.Line, n.Column, m.Line, m.Column) && e.same(n.Let, m.Let)
+	case Program:
+		m, ok := m.(Program)
+		if !ok || len(n.Stmts) != len(m.Stmts) {
+			return false
+		}
+		for i := range n.Stmts {
+			if !e.equal(n.Stmts[i], m.Stmts[i]) {
+				return false
+			}
+		}
+		return true
+	case Lambda:
+		m, ok := m.(Lambda)
+		return ok && e.symbols(n.Params, m.Params) && e.equal(n.Body, m.Body) &&
+			e.at(n.Line, n.Column, m.Line, m.Column) &&
+			e.same(n.Lparen, m.Lparen) && e.same(n.Rparen, m.Rparen)
+	case FuncDef:
+		m, ok := m.(FuncDef)
+		return ok && e.equal(n.Name, m.Name) && e.symbols(n.Params, m.Params) && e.equal(n.Body, m.Body) &&
+			e.at(n.Line, n.Column, m.Line, m.Column) &&
+			e.same(n.Lparen, m.Lparen) && e.same(n.Rparen, m.Rparen)
+	case Call:
+		m, ok := m.(Call)
+		// An empty "Args" may be nil or an empty slice. Either has length 0.
+		if !ok || !e.equal(n.Callee, m.Callee) || len(n.Args) != len(m.Args) {
+			return false
+		}
+		for i := range n.Args {
+			if !e.equal(n.Args[i], m.Args[i]) {
+				return false
+			}
+		}
+		return e.at(n.Line, n.Column, m.Line, m.Column) && e.same(n.Rparen, m.Rparen)
+	case List:
+		m, ok := m.(List)
+		return ok && e.nodes(n.Elems, m.Elems) && e.same(n.Lbrack, m.Lbrack) && e.same(n.Rbrack, m.Rbrack)
+	case Matrix:
+		m, ok := m.(Matrix)
+		if !ok || len(n.Rows) != len(m.Rows) {
+			return false
+		}
+		for i := range n.Rows {
+			if !e.nodes(n.Rows[i], m.Rows[i]) {
+				return false
+			}
+		}
+		return e.same(n.Lbrack, m.Lbrack) && e.same(n.Rbrack, m.Rbrack)
+	case Index:
+		m, ok := m.(Index)
+		return ok && e.equal(n.X, m.X) && e.nodes(n.Indices, m.Indices) &&
+			e.at(n.Line, n.Column, m.Line, m.Column) && e.same(n.Rbrack, m.Rbrack)
+	case Paren:
+		m, ok := m.(Paren)
+		return ok && e.equal(n.X, m.X) && e.same(n.Lparen, m.Lparen) && e.same(n.Rparen, m.Rparen)
+	default:
+		return false
+	}
+}
+
+// Reports whether node lists "ns" and "ms" are equal.
+func (e equality) nodes(ns, ms []Node) bool {
+	if len(ns) != len(ms) {
+		return false
+	}
+	for i := range ns {
+		if !e.equal(ns[i], ms[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Reports whether parameter lists "ps" and "qs" are equal.
+func (e equality) symbols(ps, qs []Symbol) bool {
+	if len(ps) != len(qs) {
+		return false
+	}
+	for i := range ps {
+		if !e.equal(ps[i], qs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Outputs a 64-bit FNV-1a hash of the structure of "n". Ignores
+// positions, so that nodes equal under "IgnorePositions" hash alike.
+// Stable across processes, so hashes may be stored.
+func Hash(n Node) uint64 {
+	h := fnv.New64a()
+	hashNode(h, n)
+	return h.Sum64()
+}
+
+// Node tags prefix each node's contribution to a hash.
+const (
+	tagNil byte = iota
+	tagEmpty
+	tagBad
+	tagNumber
+	tagSymbol
+	tagUnary
+	tagBinary
+	tagImpliedBinary
+	tagCall
+	tagParen
+	tagComparison
+	tagBoolean
+	tagPostfix
+	tagConditional
+	tagAssign
+	tagProgram
+	tagLambda
+	tagFuncDef
+	tagList
+	tagMatrix
+	tagIndex
+	tagString
+)
+
+func hashNode(h hash.Hash64, n Node) {
+	switch n := n.(type) {
+	case nil:
+		h.Write([]byte{tagNil})
+	case Empty:
+		h.Write([]byte{tagEmpty})
+	case Bad:
+		h.Write([]byte{tagBad})
+	case Number:
+		h.Write([]byte{tagNumber})
+		v := n.Value
+		if v == 0 {
+			v = 0 // Equates negative zero with zero.
+		}
+		hashUint(h, math.Float64bits(v))
+		hashString(h, n.Raw)
+	case Symbol:
+		h.Write([]byte{tagSymbol})
+		hashString(h, n.Value)
+	case Boolean:
+		h.Write([]byte{tagBoolean})
+		if n.Value {
+			h.Write([]byte{1})
+		} else {
+			h.Write([]byte{0})
+		}
+	case String:
+		h.Write([]byte{tagString})
+		hashString(h, n.Value)
+		hashString(h, n.Raw)
+	case Unary:
+		h.Write([]byte{tagUnary})
+		hashString(h, n.Op)
+		hashNode(h, n.X)
+	case Postfix:
+		h.Write([]byte{tagPostfix})
+		hashString(h, n.Op)
+		hashNode(h, n.X)
+	case Binary:
+		h.Write([]byte{tagBinary})
+		hashString(h, n.Op)
+		hashNode(h, n.X)
+		hashNode(h, n.Y)
+	case ImpliedBinary:
+		h.Write([]byte{tagImpliedBinary})
+		hashString(h, n.Op)
+		hashNode(h, n.X)
+		hashNode(h, n.Y)
+	case Conditional:
+		h.Write([]byte{tagConditional})
+		if n.Keyword {
+			h.Write([]byte{1})
+		} else {
+			h.Write([]byte{0})
+		}
+		hashNode(h, n.Cond)
+		hashNode(h, n.Then)
+		hashNode(h, n.Else)
+	case Assign:
+		h.Write([]byte{tagAssign})
+		hashNode(h, n.Name)
+		hashNode(h, n.Value)
+	case Program:
+		h.Write([]byte{tagProgram})
+		hashUint(h, uint64(len(n.Stmts)))
+		for _, stmt := range n.Stmts {
+			hashNode(h, stmt)
+		}
+	case Lambda:
+		h.Write([]byte{tagLambda})
+		hashSymbols(h, n.Params)
+		hashNode(h, n.Body)
+	case FuncDef:
+		h.Write([]byte{tagFuncDef})
+		hashNode(h, n.Name)
+		hashSymbols(h, n.Params)
+		hashNode(h, n.Body)
+	case Call:
+		h.Write([]byte{tagCall})
+		hashNode(h, n.Callee)
+		hashUint(h, uint64(len(n.Args)))
+		for _, arg := range n.Args {
+			hashNode(h, arg)
+		}
+	case List:
+		h.Write([]byte{tagList})
+		hashNodes(h, n.Elems)
+	case Matrix:
+		h.Write([]byte{tagMatrix})
+		hashUint(h, uint64(len(n.Rows)))
+		for _, row := range n.Rows {
+			hashNodes(h, row)
+		}
+	case Index:
+		h.Write([]byte{tagIndex})
+		hashNode(h, n.X)
+		hashNodes(h, n.Indices)
+	case Paren:
+		h.Write([]byte{tagParen})
+		hashNode(h, n.X)
+	case Comparison:
+		h.Write([]byte{tagComparison})
+		hashUint(h, uint64(len(n.Ops)))
+		for _, op := range n.Ops {
+			hashString(h, op)
+		}
+		hashUint(h, uint64(len(n.Operands)))
+		for _, x := range n.Operands {
+			hashNode(h, x)
+		}
+	}
+}
+
+func hashNodes(h hash.Hash64, nodes []Node) {
+	hashUint(h, uint64(len(nodes)))
+	for _, n := range nodes {
+		hashNode(h, n)
+	}
+}
+
+func hashSymbols(h hash.Hash64, params []Symbol) {
+	hashUint(h, uint64(len(params)))
+	for _, p := range params {
+		hashNode(h, p)
+	}
+}
+
+func hashUint(h hash.Hash64, x uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], x)
+	h.Write(b[:])
+}
+
+// Prefixes "s" with its length, so that adjacent strings cannot run together.
+func hashString(h hash.Hash64, s string) {
+	hashUint(h, uint64(len(s)))
+	h.Write([]byte(s))
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEqualNodes(t *testing.T) {
+	tests := []struct {
+		a, b      string
+		exact     bool
+		structure bool
+	}{
+		{"1 + 2 * 3", "1 + 2 * 3", true, true},
+		{"1 + 2 * 3", "1+2*3", false, true},
+		{"f(x, 2y)", "f( x,2y )", false, true},
+		{"(1 + 2) + 3", "1 + (2 + 3)", false, false},
+		{"1 × 2", "1 * 2", true, true},
+		{"x - 1", "x + 1", false, false},
+		{"7", "7.0", false, false},
+		{"2x", "2 * x", false, false},
+		{"f()", "f(1)", false, false},
+		{"", "\n", true, true},
+	}
+	for _, test := range tests {
+		a, _ := Parse(test.a)
+		b, _ := Parse(test.b)
+		if result := Equal(a, b); result != test.exact {
+			t.Errorf("TestEqualNodes failed for %q and %q. Expected: %t, Got: %t", test.a, test.b, test.exact, result)
+		}
+		if result := Equal(a, b, IgnorePositions()); result != test.structure {
+			t.Errorf("TestEqualNodes (ignoring positions) failed for %q and %q. Expected: %t, Got: %t", test.a, test.b, test.structure, result)
+		}
+		if test.structure && Hash(a) != Hash(b) {
+			t.Errorf("TestEqualNodes (hash) failed for %q and %q. Expected: equal hashes", test.a, test.b)
+		}
+		if !test.structure && Hash(a) == Hash(b) {
+			t.Errorf("TestEqualNodes (hash) failed for %q and %q. Expected: distinct hashes", test.a, test.b)
+		}
+	}
+}
+
+func TestEqualNil(t *testing.T) {
+	if !Equal(nil, nil) {
+		t.Errorf("TestEqualNil failed. Expected: nil equals nil")
+	}
+	if Equal(nil, Empty{}) || Equal(Empty{}, nil) {
+		t.Errorf("TestEqualNil failed. Expected: nil differs from Empty{}")
+	}
+	call := Call{Callee: Symbol{Value: "f"}}
+	empty := Call{Callee: Symbol{Value: "f"}, Args: []Node{}}
+	if !Equal(call, empty) || Hash(call) != Hash(empty) {
+		t.Errorf("TestEqualNil failed. Expected: nil and empty arguments alike")
+	}
+}
+
+func TestHashZero(t *testing.T) {
+	zero := Number{Value: 0}
+	negative := Number{Value: math.Copysign(0, -1)}
+	if !Equal(zero, negative) || Hash(zero) != Hash(negative) {
+		t.Errorf("TestHashZero failed. Expected: zero and negative zero alike")
+	}
+}
+
+func TestHashDeduplicate(t *testing.T) {
+	texts := []string{"x^2 + 1", "x ^ 2 + 1", "x^2+1", "1 + x^2", "x^(2 + 1)"}
+	buckets := make(map[uint64][]Node)
+	unique := 0
+	for _, text := range texts {
+		node, _ := Parse(text)
+		h := Hash(node)
+		found := false
+		for _, other := range buckets[h] {
+			if Equal(node, other, IgnorePositions()) {
+				found = true
+			}
+		}
+		if !found {
+			buckets[h] = append(buckets[h], node)
+			unique += 1
+		}
+	}
+	if unique != 3 {
+		t.Errorf("TestHashDeduplicate failed. Expected: 3, Got: %d", unique)
+	}
+}
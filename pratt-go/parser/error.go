@@ -0,0 +1,189 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"github/jared-richard-clarke/pratt/lexer"
+	"sort"
+)
+
+// Classifies parse errors.
+type ErrorKind int
+
+const (
+	LexicalError     ErrorKind = iota // Unexpected character or malformed string. Wraps a *lexer.Error.
+	UndefinedPrefix                   // Lexeme has no null denotation.
+	UndefinedInfix                    // Lexeme has no left denotation.
+	UnexpectedEOF                     // Input ends within an expression.
+	InvalidNumber                     // Number does not fit a 64-bit float.
+	MissingParen                      // Opening parenthesis has no closing match.
+	UnusedTokens                      // Tokens follow a complete expression.
+	SyntaxError                       // Raised by user-registered semantic code.
+	InputError                        // Reading source text failed. Wraps the reader's error.
+	NonAssociative                    // Non-associative operators chained where the grammar rejects chains.
+	MissingDelimiter                  // Mixfix expression lacks a delimiter, such as ":" in "c ? x : y".
+	InvalidBinding                    // Binding of something other than a name, as in "let 2 = x".
+	InvalidString                     // String literal has a malformed escape.
+)
+
+var errorKinds = [...]string{
+	LexicalError:     "lexical error",
+	UndefinedPrefix:  "undefined prefix operation",
+	UndefinedInfix:   "undefined infix operation",
+	UnexpectedEOF:    "unexpected end of input",
+	InvalidNumber:    "invalid number",
+	MissingParen:     "missing parenthesis",
+	UnusedTokens:     "unused tokens",
+	SyntaxError:      "syntax error",
+	InputError:       "input error",
+	NonAssociative:   "non-associative operators",
+	MissingDelimiter: "missing delimiter",
+	InvalidBinding:   "invalid binding",
+	InvalidString:    "invalid string",
+}
+
+func (k ErrorKind) String() string {
+	if k < 0 || int(k) >= len(errorKinds) {
+		return fmt.Sprintf("ErrorKind(%d)", int(k))
+	}
+	return errorKinds[k]
+}
+
+// Describes a parse error. Spans the offending source text from "Pos" up
+// to, but not including, "End". Retrieve it from the error returned by
+// "Parse" with "errors.As".
+type Error struct {
+	Kind  ErrorKind   // Error classification.
+	Pos   Position    // Start of offending text.
+	End   Position    // End of offending text.
+	Token lexer.Token // Offending token. Zero value for lexical errors.
+	Msg   string      // Description of error, without position.
+	Err   error       // Underlying error, if any.
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s", e.Msg, e.Pos)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// A list of parse errors. Satisfies the error interface.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Outputs the list as an error, or nil if the list is empty.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Lets "errors.Is" and "errors.As" inspect every error in the list.
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Sorts the list by starting position.
+func (l ErrorList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		return l[i].Pos.Offset < l[j].Pos.Offset
+	})
+}
+
+// Inputs a token and a formatted message. Outputs an error spanning
+// that token. Semantic code registered with a Grammar should
+// report syntax errors through "Errorf".
+func (p *Parser) Errorf(t lexer.Token, format string, args ...any) *Error {
+	return p.errorf(SyntaxError, t, format, args...)
+}
+
+func (p *Parser) errorf(kind ErrorKind, t lexer.Token, format string, args ...any) *Error {
+	pos, end := p.span(t)
+	pos.Filename = p.name
+	end.Filename = p.name
+	return &Error{
+		Kind:  kind,
+		Pos:   pos,
+		End:   end,
+		Token: t,
+		Msg:   fmt.Sprintf(format, args...),
+	}
+}
+
+// Outputs an error spanning node "n".
+func (p *Parser) errorAt(kind ErrorKind, n Node, format string, args ...any) *Error {
+	pos, end := n.Pos(), n.End()
+	pos.Filename = p.name
+	end.Filename = p.name
+	return &Error{
+		Kind: kind,
+		Pos:  pos,
+		End:  end,
+		Msg:  fmt.Sprintf(format, args...),
+	}
+}
+
+// Outputs the source span of token "t". Implied multipliers and EOF
+// occupy no space.
+func (p *Parser) span(t lexer.Token) (Position, Position) {
+	pos := p.pos(t)
+	if t.Typeof == lexer.ImpMul || t.Typeof == lexer.EOF {
+		return pos, pos
+	}
+	end := pos
+	// Spans the source text, not the value: "∧" reads "and", "!=" reads "≠".
+	end.Offset += t.Size
+	end.Column += t.Width
+	return pos, end
+}
+
+// Outputs the position of token "t". Node positions omit the file name,
+// which is the same throughout a tree.
+func (p *Parser) pos(t lexer.Token) Position {
+	return Position{
+		Offset: t.Offset,
+		Line:   t.Line,
+		Column: t.Column,
+	}
+}
+
+// Wraps an error that stopped the scan, adding the file name. A lexical
+// error keeps its position. An error reading input is positioned
+// where reading stopped.
+func (p *Parser) scanError(err error) *Error {
+	var e *lexer.Error
+	if !errors.As(err, &e) {
+		pos := p.pos(p.Peek())
+		pos.Filename = p.name
+		return &Error{
+			Kind: InputError,
+			Pos:  pos,
+			End:  pos,
+			Msg:  fmt.Sprintf("reading input: %s", err),
+			Err:  err,
+		}
+	}
+	e.Pos.Filename = p.name
+	e.End.Filename = p.name
+	return &Error{
+		Kind: LexicalError,
+		Pos:  e.Pos,
+		End:  e.End,
+		Msg:  fmt.Sprintf("%s: %q", e.Msg, e.Value),
+		Err:  e,
+	}
+}
@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"errors"
+	"github/jared-richard-clarke/pratt/lexer"
+	"testing"
+)
+
+func TestErrorSpans(t *testing.T) {
+	pos := func(o, l, c int) Position {
+		return Position{Offset: o, Line: l, Column: c}
+	}
+	tests := []struct {
+		text string
+		kind ErrorKind
+		pos  Position
+		end  Position
+	}{
+		{"1 + $", LexicalError, pos(4, 1, 5), pos(5, 1, 6)},
+		{"1 + * 7", UndefinedPrefix, pos(4, 1, 5), pos(5, 1, 6)},
+		{"1 +", UnexpectedEOF, pos(3, 1, 4), pos(3, 1, 4)},
+		{"2 × (3", MissingParen, pos(5, 1, 5), pos(6, 1, 6)},
+		{"sin(7", MissingParen, pos(3, 1, 4), pos(4, 1, 5)},
+		{"1 + 2\n 3 + 4", UnusedTokens, pos(7, 2, 2), pos(8, 2, 3)},
+		{"÷ 2", UndefinedPrefix, pos(0, 1, 1), pos(2, 1, 2)},
+	}
+	for _, test := range tests {
+		result, err := Parse(test.text)
+		var e *Error
+		if !errors.As(err, &e) {
+			t.Errorf("TestErrorSpans failed for %q. Expected: *Error, Got: %v %v", test.text, result, err)
+			continue
+		}
+		if e.Kind != test.kind {
+			t.Errorf("TestErrorSpans failed for %q. Expected: %s, Got: %s", test.text, test.kind, e.Kind)
+		}
+		if e.Pos != test.pos || e.End != test.end {
+			t.Errorf("TestErrorSpans failed for %q. Expected: %v-%v, Got: %v-%v", test.text, test.pos, test.end, e.Pos, e.End)
+		}
+	}
+}
+
+func TestLexicalErrorUnwraps(t *testing.T) {
+	_, err := Parse("1 + @")
+	var e *lexer.Error
+	if !errors.As(err, &e) {
+		t.Fatalf("TestLexicalErrorUnwraps failed. Expected: *lexer.Error, Got: %v", err)
+	}
+	if e.Value != "@" || e.Pos.Column != 5 {
+		t.Errorf("TestLexicalErrorUnwraps failed. Expected: \"@\" at column 5, Got: %q at column %d", e.Value, e.Pos.Column)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	_, err := Parse("1 + * 7")
+	expect := `undefined prefix operation "*" line:1 column:5`
+	if err == nil || err.Error() != expect {
+		t.Errorf("TestErrorMessage failed. Expected: %s, Got: %v", expect, err)
+	}
+}
+
+func TestErrorFilename(t *testing.T) {
+	fset := NewFileSet()
+	fset.AddFile("a.txt", "1 + 2")
+	f := fset.AddFile("b.txt", "1 +\n* 7")
+	_, err := ParseFile(f)
+	expect := `undefined prefix operation "*" file:b.txt line:2 column:1`
+	if err == nil || err.Error() != expect {
+		t.Errorf("TestErrorFilename failed. Expected: %s, Got: %v", expect, err)
+	}
+	var e *Error
+	if errors.As(err, &e) && fset.Position(f.Pos(e.Pos.Offset)) != e.Pos {
+		t.Errorf("TestErrorFilename failed. Expected: %v, Got: %v", e.Pos, fset.Position(f.Pos(e.Pos.Offset)))
+	}
+	_, errs := ParseAllFile(NewFile("c.txt", "$ + 1 +"))
+	if len(errs) != 2 {
+		t.Fatalf("TestErrorFilename failed. Expected: 2 errors, Got: %v", errs)
+	}
+	for _, e := range errs {
+		if e.Pos.Filename != "c.txt" || e.End.Filename != "c.txt" {
+			t.Errorf("TestErrorFilename failed. Expected: c.txt, Got: %q %q", e.Pos.Filename, e.End.Filename)
+		}
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	text := "1 + * 7 + f(2, ) + (3 $)"
+	expect := Binary{
+		Op: "+",
+		X: Binary{
+			Op: "+",
+			X: Binary{
+				Op: "+",
+				X: Number{
+					Value:  1.0,
+					Raw:    "1",
+					Line:   1,
+					Column: 1,
+				},
+				Y: Bad{
+					Line:   1,
+					Column: 5,
+					Offset: 4,
+					To: Position{
+						Offset: 7,
+						Line:   1,
+						Column: 8,
+					},
+				},
+				Line:   1,
+				Column: 3,
+			},
+			Y: Call{
+				Callee: Symbol{
+					Value:  "f",
+					Line:   1,
+					Column: 11,
+				},
+				Args: []Node{
+					Number{
+						Value:  2.0,
+						Raw:    "2",
+						Line:   1,
+						Column: 13,
+					},
+					Bad{
+						Line:   1,
+						Column: 16,
+						Offset: 15,
+						To: Position{
+							Offset: 15,
+							Line:   1,
+							Column: 16,
+						},
+					},
+				},
+				Line:   1,
+				Column: 12,
+				Rparen: Position{
+					Offset: 15,
+					Line:   1,
+					Column: 16,
+				},
+			},
+			Line:   1,
+			Column: 9,
+		},
+		Y: Paren{
+			X: Number{
+				Value:  3.0,
+				Raw:    "3",
+				Line:   1,
+				Column: 21,
+			},
+			Lparen: Position{
+				Offset: 19,
+				Line:   1,
+				Column: 20,
+			},
+			Rparen: Position{
+				Offset: 23,
+				Line:   1,
+				Column: 24,
+			},
+		},
+		Line:   1,
+		Column: 18,
+	}
+	kinds := []ErrorKind{UndefinedPrefix, UndefinedPrefix, LexicalError}
+	result, errs := ParseAll(text)
+	if !Equal(expect, result) {
+		t.Errorf("TestParseAll failed. Expected: %s, Got: %s", expect, result)
+	}
+	if len(errs) != len(kinds) {
+		t.Fatalf("TestParseAll failed. Expected: %d errors, Got: %v", len(kinds), errs)
+	}
+	for i, e := range errs {
+		if e.Kind != kinds[i] {
+			t.Errorf("TestParseAll failed. Expected: %s, Got: %s", kinds[i], e)
+		}
+	}
+}
+
+func TestParseAllValid(t *testing.T) {
+	result, errs := ParseAll("sum(7, 11x)")
+	if errs != nil {
+		t.Errorf("TestParseAllValid failed. Expected: no errors, Got: %v", errs)
+	}
+	if _, ok := result.(Call); !ok {
+		t.Errorf("TestParseAllValid failed. Expected: Call, Got: %s", result)
+	}
+}
+
+func TestParseAllSync(t *testing.T) {
+	tests := []struct {
+		text  string
+		count int
+	}{
+		{"1 +", 1},
+		{"(1 + 2", 1},
+		{"sin(7", 1},
+		{"1 + )", 2},
+		{"$ + @ + 3", 2},
+		{"1 + 2 3", 1},
+		{"f(, , 1)", 2},
+		{"* (1, 2) + 3", 1},
+	}
+	for _, test := range tests {
+		_, errs := ParseAll(test.text)
+		if len(errs) != test.count {
+			t.Errorf("TestParseAllSync failed for %q. Expected: %d errors, Got: %v", test.text, test.count, errs)
+		}
+	}
+}
+
+func TestErrorListAs(t *testing.T) {
+	_, errs := ParseAll("1 + * 2 + (")
+	var e *Error
+	if !errors.As(errs.Err(), &e) || e.Kind != UndefinedPrefix {
+		t.Errorf("TestErrorListAs failed. Expected: %s, Got: %v", UndefinedPrefix, errs)
+	}
+	if ErrorList(nil).Err() != nil {
+		t.Errorf("TestErrorListAs failed. Expected: nil error from empty list")
+	}
+}
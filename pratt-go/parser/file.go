@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"github/jared-richard-clarke/pratt/lexer"
+	"io"
+)
+
+// Position mirrors the lexer's type: a file name, a byte offset,
+// a line, and a column counted in runes.
+type Position = lexer.Position
+
+// Source files and their positions, mirroring the lexer's types.
+// A File converts byte offsets into lines and columns counted in
+// runes, bytes, or UTF-16 code units. A FileSet holds several files,
+// each identified by its own range of Pos values.
+type (
+	File    = lexer.File
+	FileSet = lexer.FileSet
+	Pos     = lexer.Pos
+	Unit    = lexer.Unit
+)
+
+const NoPos = lexer.NoPos
+
+// Units in which a column may be counted.
+const (
+	Runes = lexer.Runes
+	Bytes = lexer.Bytes
+	UTF16 = lexer.UTF16
+)
+
+// Inputs a file name and source text, outputs a File standing alone.
+func NewFile(name, src string) *File {
+	return lexer.NewFile(name, src)
+}
+
+// Outputs an empty FileSet.
+func NewFileSet() *FileSet {
+	return lexer.NewFileSet()
+}
+
+// Parser API: inputs a file, outputs either AST or Error. Parses with
+// the default arithmetic grammar. Safe for concurrent use.
+func ParseFile(f *File) (Node, error) {
+	return New(nil).ParseFile(f)
+}
+
+// Parser API: inputs a file, outputs an AST together with every error
+// found along the way. Parses with the default arithmetic grammar.
+func ParseAllFile(f *File) (Node, ErrorList) {
+	return New(nil).ParseAllFile(f)
+}
+
+// Parser API: inputs a reader, outputs either AST or Error. Scans tokens
+// only as the parser needs them. Parses with the default arithmetic
+// grammar.
+func ParseReader(name string, r io.Reader) (Node, error) {
+	return New(nil).ParseReader(name, r)
+}
+
+// Parser API: inputs a reader, outputs an AST together with every error
+// found along the way. Parses with the default arithmetic grammar.
+func ParseAllReader(name string, r io.Reader) (Node, ErrorList) {
+	return New(nil).ParseAllReader(name, r)
+}
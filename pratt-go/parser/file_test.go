@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// Outputs "1 + 1 + ... + 1" with "n" terms, generating the text as it is
+// read. A negative "n" never ends.
+type terms struct {
+	n    int
+	rest string
+}
+
+func (r *terms) Read(b []byte) (int, error) {
+	for r.rest == "" {
+		switch {
+		case r.n == 0:
+			return 0, io.EOF
+		case r.n == 1:
+			r.rest = "1"
+		default:
+			r.rest = "1 + "
+		}
+		r.n -= 1
+	}
+	n := copy(b, r.rest)
+	r.rest = r.rest[n:]
+	return n, nil
+}
+
+func TestParseReader(t *testing.T) {
+	const n = 100000
+	node, err := ParseReader("terms", &terms{n: n})
+	if err != nil {
+		t.Fatalf("TestParseReader failed. Got: %s", err)
+	}
+	count := 0
+	Inspect(node, func(n Node) bool {
+		if _, ok := n.(Number); ok {
+			count += 1
+		}
+		return true
+	})
+	if count != n {
+		t.Errorf("TestParseReader failed. Expected: %d, Got: %d", n, count)
+	}
+	if end := node.End().Offset; end != 4*n-3 {
+		t.Errorf("TestParseReader failed. Expected: %d, Got: %d", 4*n-3, end)
+	}
+}
+
+func TestParseReaderErrors(t *testing.T) {
+	// The lexical error follows the syntax error, within the parser's
+	// lookahead, and takes precedence.
+	_, err := ParseReader("a.txt", iotest.OneByteReader(strings.NewReader("1 + * $")))
+	var e *Error
+	if !errors.As(err, &e) || e.Kind != LexicalError || e.Pos.Filename != "a.txt" {
+		t.Errorf("TestParseReaderErrors failed. Expected: %s, Got: %v", LexicalError, err)
+	}
+
+	failure := errors.New("failure")
+	r := io.MultiReader(strings.NewReader("1 + 2"), iotest.ErrReader(failure))
+	_, err = ParseReader("", r)
+	if !errors.As(err, &e) || e.Kind != InputError || !errors.Is(err, failure) {
+		t.Errorf("TestParseReaderErrors failed. Expected: %s, Got: %v", InputError, err)
+	}
+
+	// Input beyond the first error is left unread, so an endless
+	// stream still ends in an error.
+	for _, text := range []string{"1 + * 2 + ", "1 2 + "} {
+		_, err = ParseReader("", io.MultiReader(strings.NewReader(text), &terms{n: -1}))
+		if !errors.As(err, &e) || e.Kind == InputError {
+			t.Errorf("TestParseReaderErrors failed for %q. Expected: syntax error, Got: %v", text, err)
+		}
+	}
+
+	r = io.MultiReader(strings.NewReader("1 + $"), iotest.ErrReader(failure))
+	_, errs := ParseAllReader("", r)
+	kinds := []ErrorKind{LexicalError, InputError}
+	if len(errs) != len(kinds) {
+		t.Fatalf("TestParseReaderErrors failed. Expected: %v, Got: %v", kinds, errs)
+	}
+	for i, e := range errs {
+		if e.Kind != kinds[i] {
+			t.Errorf("TestParseReaderErrors failed. Expected: %s, Got: %s", kinds[i], e.Kind)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"github/jared-richard-clarke/pratt/lexer"
+	"math"
+)
+
+// Token and LexType alias the lexer's types, so that handlers may be
+// written against this package alone.
+type (
+	Token   = lexer.Token
+	LexType = lexer.LexType
+)
+
+// Lexeme types, as produced by the lexer. Prefixed with "Lex"
+// to keep them apart from the AST node types.
+const (
+	LexOpenParen    = lexer.OpenParen
+	LexCloseParen   = lexer.CloseParen
+	LexComma        = lexer.Comma
+	LexEqual        = lexer.Equal
+	LexNotEqual     = lexer.NotEqual
+	LexAdd          = lexer.Add
+	LexSub          = lexer.Sub
+	LexMul          = lexer.Mul
+	LexImpMul       = lexer.ImpMul
+	LexDiv          = lexer.Div
+	LexPow          = lexer.Pow
+	LexNumber       = lexer.Number
+	LexSymbol       = lexer.Symbol
+	LexIllegal      = lexer.Illegal
+	LexEOF          = lexer.EOF
+	LexLess         = lexer.Less
+	LexGreater      = lexer.Greater
+	LexLessEqual    = lexer.LessEqual
+	LexGreaterEqual = lexer.GreaterEqual
+	LexAnd          = lexer.And
+	LexOr           = lexer.Or
+	LexNot          = lexer.Not
+	LexBoolean      = lexer.Boolean
+	LexFactorial    = lexer.Factorial
+	LexPercent      = lexer.Percent
+	LexPrime        = lexer.Prime
+	LexQuestion     = lexer.Question
+	LexColon        = lexer.Colon
+	LexIf           = lexer.If
+	LexThen         = lexer.Then
+	LexElse         = lexer.Else
+	LexSemicolon    = lexer.Semicolon
+	LexLet          = lexer.Let
+	LexDefine       = lexer.Define
+	LexArrow        = lexer.Arrow
+	LexOpenBracket  = lexer.OpenBracket
+	LexCloseBracket = lexer.CloseBracket
+	LexString       = lexer.String
+)
+
+// Null denotation: parses a lexeme without a left expression —
+// numbers, symbols, groupings, and prefix operators.
+type NudFunc func(p *Parser, t Token) (Node, error)
+
+// Left denotation: parses a lexeme with a left expression —
+// infix, postfix, and mixfix operators.
+type LedFunc func(p *Parser, left Node, t Token) (Node, error)
+
+// Associativity of infix operators.
+type Assoc int
+
+const (
+	Left     Assoc = iota
+	Right          // "a ^ b ^ c" reads "a ^ (b ^ c)".
+	NonAssoc       // "a < b < c" reads as a chain, by the grammar's ChainPolicy.
+)
+
+// Treatment of chains of non-associative operators, such as "a < b < c".
+// A chain may mix operators of equal binding power: "0 ≤ x < 10".
+type ChainPolicy int
+
+const (
+	ChainComparisons ChainPolicy = iota // Outputs a Comparison node, reading "a < b ∧ b < c".
+	RejectChains                        // Reports an error at the second operator.
+)
+
+// Grammar maps lexemes to their semantic code and binding powers.
+// A Grammar must not be modified while a parser reads from it. Parsers
+// built by "New" hold their own copy.
+type Grammar struct {
+	nud    map[LexType]NudFunc // lexeme -> nud
+	led    map[LexType]LedFunc // lexeme -> led
+	bind   map[LexType]int     // lexeme -> left binding power
+	prefix map[LexType]int     // lexeme -> prefix binding power
+	assoc  map[LexType]Assoc   // lexeme -> associativity, infix operators only
+	chain  ChainPolicy         // treatment of non-associative chains
+}
+
+// Outputs a grammar that recognizes nothing but the end of input
+// and the unexpected characters reported by the lexer.
+func NewGrammar() *Grammar {
+	g := &Grammar{
+		nud:    make(map[LexType]NudFunc),
+		led:    make(map[LexType]LedFunc),
+		bind:   make(map[LexType]int),
+		prefix: make(map[LexType]int),
+		assoc:  make(map[LexType]Assoc),
+	}
+	g.Nud(lexer.EOF, (*Parser).parseEOF)
+	g.Nud(lexer.Illegal, (*Parser).parseIllegal)
+	g.Led(lexer.Illegal, math.MaxInt, (*Parser).skipIllegal)
+	return g
+}
+
+// Outputs a copy of the default arithmetic grammar, ready for extension.
+func DefaultGrammar() *Grammar {
+	return grammar.Clone()
+}
+
+// Outputs a deep copy of "g".
+func (g *Grammar) Clone() *Grammar {
+	c := &Grammar{
+		nud:    make(map[LexType]NudFunc, len(g.nud)),
+		led:    make(map[LexType]LedFunc, len(g.led)),
+		bind:   make(map[LexType]int, len(g.bind)),
+		prefix: make(map[LexType]int, len(g.prefix)),
+		assoc:  make(map[LexType]Assoc, len(g.assoc)),
+		chain:  g.chain,
+	}
+	for t, n := range g.nud {
+		c.nud[t] = n
+	}
+	for t, l := range g.led {
+		c.led[t] = l
+	}
+	for t, bp := range g.bind {
+		c.bind[t] = bp
+	}
+	for t, bp := range g.prefix {
+		c.prefix[t] = bp
+	}
+	for t, a := range g.assoc {
+		c.assoc[t] = a
+	}
+	return c
+}
+
+// Registers a null denotation for "t". Replaces any existing nud.
+func (g *Grammar) Nud(t LexType, n NudFunc) {
+	g.nud[t] = n
+}
+
+// Registers a left denotation for "t" with left binding power "bp".
+// Replaces any existing led. Postfix and mixfix operators are built
+// from leds: a postfix led returns without consuming further tokens,
+// while a mixfix led consumes whatever operands and delimiters it needs
+// through "ParseExpression", "Next", and "Match".
+func (g *Grammar) Led(t LexType, bp int, l LedFunc) {
+	g.led[t] = l
+	g.bind[t] = bp
+	delete(g.assoc, t)
+}
+
+// Registers "t" as a prefix operator whose operand binds at "bp".
+// Outputs Unary nodes.
+func (g *Grammar) Prefix(t LexType, bp int) {
+	g.nud[t] = (*Parser).parseUnary
+	g.prefix[t] = bp
+}
+
+// Registers "t" as a postfix operator with left binding power "bp".
+// Outputs Postfix nodes.
+func (g *Grammar) Postfix(t LexType, bp int) {
+	g.Led(t, bp, (*Parser).parsePostfix)
+}
+
+// Registers "t" as an infix operator with binding power "bp" and
+// associativity "a". Outputs Binary nodes, or ImpliedBinary for ImpMul.
+// Chains of non-associative operators output Comparison nodes.
+func (g *Grammar) Infix(t LexType, bp int, a Assoc) {
+	switch a {
+	case Right:
+		g.Led(t, bp, (*Parser).parseBinaryRight)
+	case NonAssoc:
+		g.Led(t, bp, (*Parser).parseNonAssoc)
+	default:
+		g.Led(t, bp, (*Parser).parseBinaryLeft)
+	}
+	g.assoc[t] = a
+}
+
+// Sets the treatment of chains of non-associative operators. The
+// default grammar chains comparisons.
+func (g *Grammar) Chain(policy ChainPolicy) {
+	g.chain = policy
+}
+
+// Removes every denotation of "t" from the grammar.
+func (g *Grammar) Delete(t LexType) {
+	delete(g.nud, t)
+	delete(g.led, t)
+	delete(g.bind, t)
+	delete(g.prefix, t)
+	delete(g.assoc, t)
+}
+
+// Default arithmetic grammar shared by every call to "Parse". Built once
+// during package initialization and never written to afterwards, so any
+// number of parsers may read from it concurrently.
+var grammar *Grammar
+
+func init() {
+	g := NewGrammar()
+	g.Nud(lexer.Number, (*Parser).parseNumber)
+	g.Nud(lexer.String, (*Parser).parseString)
+	g.Nud(lexer.Symbol, (*Parser).parseSymbol)
+	g.Nud(lexer.Boolean, (*Parser).parseBoolean)
+	g.Nud(lexer.OpenParen, (*Parser).parseGrouping)
+	g.Nud(lexer.OpenBracket, (*Parser).parseBrackets)
+	g.Nud(lexer.If, (*Parser).parseIf)
+	g.Led(lexer.Define, 1, (*Parser).parseFuncDef)
+	g.Led(lexer.Question, 2, (*Parser).parseTernary)
+	g.Infix(lexer.Or, 4, Left)
+	g.Infix(lexer.And, 6, Left)
+	g.Prefix(lexer.Not, 8)
+	g.Prefix(lexer.Add, 20)
+	g.Prefix(lexer.Sub, 20)
+	g.Infix(lexer.Equal, 10, NonAssoc)
+	g.Infix(lexer.NotEqual, 10, NonAssoc)
+	g.Infix(lexer.Less, 10, NonAssoc)
+	g.Infix(lexer.Greater, 10, NonAssoc)
+	g.Infix(lexer.LessEqual, 10, NonAssoc)
+	g.Infix(lexer.GreaterEqual, 10, NonAssoc)
+	g.Infix(lexer.Add, 20, Left)
+	g.Infix(lexer.Sub, 20, Left)
+	g.Infix(lexer.Mul, 30, Left)
+	g.Infix(lexer.Div, 30, Left)
+	g.Infix(lexer.ImpMul, 40, Left)
+	g.Infix(lexer.Pow, 50, Right)
+	g.Postfix(lexer.Factorial, 55)
+	g.Postfix(lexer.Percent, 55)
+	g.Led(lexer.OpenParen, 60, (*Parser).parseCall)
+	g.Led(lexer.OpenBracket, 60, (*Parser).parseIndex)
+	g.Postfix(lexer.Prime, 70)
+	grammar = g
+}
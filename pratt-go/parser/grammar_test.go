@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRightAssociativeSub(t *testing.T) {
+	g := DefaultGrammar()
+	g.Infix(LexSub, 20, Right)
+	text := "1 - 2 - 3"
+	expect := Binary{
+		Op: "-",
+		X: Number{
+			Value:  1.0,
+			Raw:    "1",
+			Line:   1,
+			Column: 1,
+		},
+		Y: Binary{
+			Op: "-",
+			X: Number{
+				Value:  2.0,
+				Raw:    "2",
+				Line:   1,
+				Column: 5,
+			},
+			Y: Number{
+				Value:  3.0,
+				Raw:    "3",
+				Line:   1,
+				Column: 9,
+			},
+			Line:   1,
+			Column: 7,
+		},
+		Line:   1,
+		Column: 3,
+	}
+	result, err := New(g).Parse(text)
+	if err != nil {
+		t.Errorf("TestRightAssociativeSub failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestRightAssociativeSub failed. Expected: %s, Got: %s", expect, result)
+	}
+	// The default grammar is untouched.
+	result, err = Parse(text)
+	if err != nil {
+		t.Errorf("TestRightAssociativeSub (default) failed. Expected: Binary, Got: %s", err)
+	}
+	if Equal(expect, result) {
+		t.Errorf("TestRightAssociativeSub (default) failed. Got right association: %s", result)
+	}
+}
+
+func TestPrefixBindingPower(t *testing.T) {
+	g := DefaultGrammar()
+	g.Prefix(LexSub, 60)
+	text := "-x^2"
+	expect := Binary{
+		Op: "^",
+		X: Unary{
+			Op: "-",
+			X: Symbol{
+				Value:  "x",
+				Line:   1,
+				Column: 2,
+			},
+			Line:   1,
+			Column: 1,
+		},
+		Y: Number{
+			Value:  2.0,
+			Raw:    "2",
+			Line:   1,
+			Column: 4,
+		},
+		Line:   1,
+		Column: 3,
+	}
+	result, err := New(g).Parse(text)
+	if err != nil {
+		t.Errorf("TestPrefixBindingPower failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestPrefixBindingPower failed. Expected: %s, Got: %s", expect, result)
+	}
+}
+
+func TestPostfix(t *testing.T) {
+	// "x/" reads as the reciprocal of "x".
+	g := DefaultGrammar()
+	g.Led(LexDiv, 70, func(p *Parser, left Node, t Token) (Node, error) {
+		return Call{
+			Callee: Symbol{Value: "inv", Line: t.Line, Column: t.Column},
+			Args:   []Node{left},
+			Line:   t.Line,
+			Column: t.Column,
+		}, nil
+	})
+	text := "2 + x/"
+	expect := Binary{
+		Op: "+",
+		X: Number{
+			Value:  2.0,
+			Raw:    "2",
+			Line:   1,
+			Column: 1,
+		},
+		Y: Call{
+			Callee: Symbol{
+				Value:  "inv",
+				Line:   1,
+				Column: 6,
+			},
+			Args: []Node{
+				Symbol{
+					Value:  "x",
+					Line:   1,
+					Column: 5,
+				},
+			},
+			Line:   1,
+			Column: 6,
+		},
+		Line:   1,
+		Column: 3,
+	}
+	result, err := New(g).Parse(text)
+	if err != nil {
+		t.Errorf("TestPostfix failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestPostfix failed. Expected: %s, Got: %s", expect, result)
+	}
+}
+
+func TestMixfix(t *testing.T) {
+	// "x = a, b" reads as "between(x, a, b)".
+	g := DefaultGrammar()
+	g.Led(LexEqual, 10, func(p *Parser, left Node, t Token) (Node, error) {
+		lo, err := p.ParseExpression(10)
+		if err != nil {
+			return nil, err
+		}
+		if !p.Match(LexComma) {
+			return nil, p.Errorf(t, "expected ','")
+		}
+		p.Next()
+		hi, err := p.ParseExpression(10)
+		if err != nil {
+			return nil, err
+		}
+		return Call{
+			Callee: Symbol{Value: "between", Line: t.Line, Column: t.Column},
+			Args:   []Node{left, lo, hi},
+			Line:   t.Line,
+			Column: t.Column,
+		}, nil
+	})
+	p := New(g)
+	result, err := p.Parse("x = 1, 2 + 3")
+	if err != nil {
+		t.Fatalf("TestMixfix failed. Expected: Call, Got: %s", err)
+	}
+	call, ok := result.(Call)
+	if !ok || len(call.Args) != 3 {
+		t.Fatalf("TestMixfix failed. Expected: Call with 3 arguments, Got: %s", result)
+	}
+	if _, ok := call.Args[2].(Binary); !ok {
+		t.Errorf("TestMixfix failed. Expected: Binary third argument, Got: %s", call.Args[2])
+	}
+	if result, err := p.Parse("x = 1"); err == nil {
+		t.Errorf("TestMixfix failed. Expected: error, Got: %s", result)
+	}
+}
+
+func TestGrammarCopied(t *testing.T) {
+	g := DefaultGrammar()
+	p := New(g)
+	g.Delete(LexImpMul)
+	if _, err := p.Parse("2x"); err != nil {
+		t.Errorf("TestGrammarCopied failed. Parser changed with its grammar: %s", err)
+	}
+	if result, err := New(g).Parse("2x"); err == nil {
+		t.Errorf("TestGrammarCopied failed. Expected: error, Got: %s", result)
+	}
+}
+
+func TestParserOutsideParse(t *testing.T) {
+	p := New(nil)
+	if token := p.Peek(); token.Typeof != LexEOF {
+		t.Errorf("TestParserOutsideParse failed. Expected: EOF, Got: %v", token)
+	}
+	if token := p.Next(); token.Typeof != LexEOF {
+		t.Errorf("TestParserOutsideParse failed. Expected: EOF, Got: %v", token)
+	}
+	result, err := p.ParseExpression(0)
+	if e, ok := err.(*Error); !ok || e.Kind != InputError {
+		t.Errorf("TestParserOutsideParse failed. Expected: input error, Got: %v %v", result, err)
+	}
+	if result, err := p.Parse("1 + 2"); err != nil {
+		t.Errorf("TestParserOutsideParse failed. Expected: 1 + 2, Got: %v %v", result, err)
+	}
+}
+
+func TestEmptyGrammar(t *testing.T) {
+	p := New(NewGrammar())
+	result, err := p.Parse("")
+	if err != nil || !Equal(Empty{}, result) {
+		t.Errorf("TestEmptyGrammar failed. Expected: Empty{}, Got: %v %v", result, err)
+	}
+	if result, err := p.Parse("7"); err == nil {
+		t.Errorf("TestEmptyGrammar failed. Expected: error, Got: %s", result)
+	}
+}
+
+func TestChainPolicy(t *testing.T) {
+	g := DefaultGrammar()
+	g.Chain(RejectChains)
+	p := New(g)
+	if _, err := p.Parse("a < b"); err != nil {
+		t.Errorf("TestChainPolicy failed. Expected: no error, Got: %s", err)
+	}
+	if _, err := p.Parse("(a < b) < c"); err != nil {
+		t.Errorf("TestChainPolicy failed. Expected: no error, Got: %s", err)
+	}
+	_, err := p.Parse("0 ≤ x < 10")
+	var e *Error
+	if !errors.As(err, &e) || e.Kind != NonAssociative || e.Pos.Column != 7 {
+		t.Errorf("TestChainPolicy failed. Expected: %s at column 7, Got: %v", NonAssociative, err)
+	}
+	// Recovering keeps the chain.
+	node, errs := p.ParseAll("a = b = c")
+	if _, ok := node.(Comparison); !ok || len(errs) != 1 {
+		t.Errorf("TestChainPolicy failed. Expected: Comparison and 1 error, Got: %v %v", node, errs)
+	}
+	// The default grammar is unchanged.
+	if node, err := Parse("0 ≤ x < 10"); err != nil {
+		t.Errorf("TestChainPolicy failed. Expected: Comparison, Got: %v %v", node, err)
+	}
+}
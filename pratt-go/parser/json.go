@@ -0,0 +1,1193 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// JSON encodings of the AST. Every node is an object whose "type" field
+// names its Go type. Child nodes nest as objects. Since a Node is an
+// interface, decode trees of unknown shape with "UnmarshalNode".
+//
+//	{"type":"ImpliedBinary","op":"*",
+//	 "x":{"type":"Number","value":7,"raw":"7","line":1,"column":1,"offset":0},
+//	 "y":{"type":"Symbol","value":"x","line":1,"column":2,"offset":1},
+//	 "line":1,"column":2,"offset":1}
+
+type jsonEmpty struct {
+	Type string `json:"type"`
+}
+
+type jsonBad struct {
+	Type   string        `json:"type"`
+	Line   int           `json:"line"`
+	Column int           `json:"column"`
+	Offset int           `json:"offset"`
+	To     *jsonPosition `json:"to,omitempty"`
+}
+
+type jsonNumber struct {
+	Type   string  `json:"type"`
+	Value  float64 `json:"value"`
+	Raw    string  `json:"raw,omitempty"`
+	Line   int     `json:"line"`
+	Column int     `json:"column"`
+	Offset int     `json:"offset"`
+}
+
+type jsonSymbol struct {
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Offset int    `json:"offset"`
+}
+
+type jsonBoolean struct {
+	Type   string `json:"type"`
+	Value  bool   `json:"value"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Offset int    `json:"offset"`
+}
+
+type jsonString struct {
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Raw    string `json:"raw,omitempty"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Offset int    `json:"offset"`
+}
+
+type jsonUnary struct {
+	Type   string          `json:"type"`
+	Op     string          `json:"op"`
+	X      json.RawMessage `json:"x"`
+	Line   int             `json:"line"`
+	Column int             `json:"column"`
+	Offset int             `json:"offset"`
+}
+
+type jsonPostfix struct {
+	Type   string          `json:"type"`
+	Op     string          `json:"op"`
+	X      json.RawMessage `json:"x"`
+	Line   int             `json:"line"`
+	Column int             `json:"column"`
+	Offset int             `json:"offset"`
+	Size   int             `json:"size,omitempty"`
+}
+
+type jsonBinary struct {
+	Type   string          `json:"type"`
+	Op     string          `json:"op"`
+	X      json.RawMessage `json:"x"`
+	Y      json.RawMessage `json:"y"`
+	Line   int             `json:"line"`
+	Column int             `json:"column"`
+	Offset int             `json:"offset"`
+}
+
+type jsonImpliedBinary struct {
+	Type   string          `json:"type"`
+	Op     string          `json:"op"`
+	X      json.RawMessage `json:"x"`
+	Y      json.RawMessage `json:"y"`
+	Line   int             `json:"line"`
+	Column int             `json:"column"`
+	Offset int             `json:"offset"`
+}
+
+type jsonComparison struct {
+	Type     string            `json:"type"`
+	Ops      []string          `json:"ops"`
+	Operands []json.RawMessage `json:"operands"`
+	Line     int               `json:"line"`
+	Column   int               `json:"column"`
+	Offset   int               `json:"offset"`
+}
+
+type jsonConditional struct {
+	Type    string          `json:"type"`
+	Cond    json.RawMessage `json:"cond"`
+	Then    json.RawMessage `json:"then"`
+	Else    json.RawMessage `json:"else"`
+	Keyword bool            `json:"keyword,omitempty"`
+	Line    int             `json:"line"`
+	Column  int             `json:"column"`
+	Offset  int             `json:"offset"`
+	ThenPos *jsonPosition   `json:"thenPos,omitempty"`
+	ElsePos *jsonPosition   `json:"elsePos,omitempty"`
+}
+
+type jsonAssign struct {
+	Type   string          `json:"type"`
+	Name   json.RawMessage `json:"name"`
+	Value  json.RawMessage `json:"value"`
+	Line   int             `json:"line"`
+	Column int             `json:"column"`
+	Offset int             `json:"offset"`
+	Let    *jsonPosition   `json:"let,omitempty"`
+}
+
+type jsonProgram struct {
+	Type  string            `json:"type"`
+	Stmts []json.RawMessage `json:"stmts"`
+}
+
+type jsonLambda struct {
+	Type   string            `json:"type"`
+	Params []json.RawMessage `json:"params"`
+	Body   json.RawMessage   `json:"body"`
+	Line   int               `json:"line"`
+	Column int               `json:"column"`
+	Offset int               `json:"offset"`
+	Lparen *jsonPosition     `json:"lparen,omitempty"`
+	Rparen *jsonPosition     `json:"rparen,omitempty"`
+}
+
+type jsonFuncDef struct {
+	Type   string            `json:"type"`
+	Name   json.RawMessage   `json:"name"`
+	Params []json.RawMessage `json:"params"`
+	Body   json.RawMessage   `json:"body"`
+	Line   int               `json:"line"`
+	Column int               `json:"column"`
+	Offset int               `json:"offset"`
+	Lparen *jsonPosition     `json:"lparen,omitempty"`
+	Rparen *jsonPosition     `json:"rparen,omitempty"`
+}
+
+type jsonCall struct {
+	Type   string            `json:"type"`
+	Callee json.RawMessage   `json:"callee"`
+	Args   []json.RawMessage `json:"args"`
+	Line   int               `json:"line"`
+	Column int               `json:"column"`
+	Offset int               `json:"offset"`
+	Rparen *jsonPosition     `json:"rparen,omitempty"`
+}
+
+type jsonList struct {
+	Type   string            `json:"type"`
+	Elems  []json.RawMessage `json:"elems"`
+	Lbrack *jsonPosition     `json:"lbrack,omitempty"`
+	Rbrack *jsonPosition     `json:"rbrack,omitempty"`
+}
+
+type jsonMatrix struct {
+	Type   string              `json:"type"`
+	Rows   [][]json.RawMessage `json:"rows"`
+	Lbrack *jsonPosition       `json:"lbrack,omitempty"`
+	Rbrack *jsonPosition       `json:"rbrack,omitempty"`
+}
+
+type jsonIndex struct {
+	Type    string            `json:"type"`
+	X       json.RawMessage   `json:"x"`
+	Indices []json.RawMessage `json:"indices"`
+	Line    int               `json:"line"`
+	Column  int               `json:"column"`
+	Offset  int               `json:"offset"`
+	Rbrack  *jsonPosition     `json:"rbrack,omitempty"`
+}
+
+type jsonParen struct {
+	Type   string          `json:"type"`
+	X      json.RawMessage `json:"x"`
+	Lparen *jsonPosition   `json:"lparen,omitempty"`
+	Rparen *jsonPosition   `json:"rparen,omitempty"`
+}
+
+type jsonPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+// Outputs nil for the zero position, which stands for missing text.
+func toPosition(p Position) *jsonPosition {
+	if p == (Position{}) {
+		return nil
+	}
+	return &jsonPosition{
+		Line:   p.Line,
+		Column: p.Column,
+		Offset: p.Offset,
+	}
+}
+
+func fromPosition(p *jsonPosition) Position {
+	if p == nil {
+		return Position{}
+	}
+	return Position{
+		Offset: p.Offset,
+		Line:   p.Line,
+		Column: p.Column,
+	}
+}
+
+func (e Empty) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonEmpty{Type: "Empty"})
+}
+
+func (b Bad) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonBad{
+		Type:   "Bad",
+		Line:   b.Line,
+		Column: b.Column,
+		Offset: b.Offset,
+		To:     toPosition(b.To),
+	})
+}
+
+func (n Number) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonNumber{
+		Type:   "Number",
+		Value:  n.Value,
+		Raw:    n.Raw,
+		Line:   n.Line,
+		Column: n.Column,
+		Offset: n.Offset,
+	})
+}
+
+func (s Symbol) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonSymbol{
+		Type:   "Symbol",
+		Value:  s.Value,
+		Line:   s.Line,
+		Column: s.Column,
+		Offset: s.Offset,
+	})
+}
+
+func (b Boolean) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonBoolean{
+		Type:   "Boolean",
+		Value:  b.Value,
+		Line:   b.Line,
+		Column: b.Column,
+		Offset: b.Offset,
+	})
+}
+
+func (s String) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonString{
+		Type:   "String",
+		Value:  s.Value,
+		Raw:    s.Raw,
+		Line:   s.Line,
+		Column: s.Column,
+		Offset: s.Offset,
+	})
+}
+
+func (u Unary) MarshalJSON() ([]byte, error) {
+	x, err := json.Marshal(u.X)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonUnary{
+		Type:   "Unary",
+		Op:     u.Op,
+		X:      x,
+		Line:   u.Line,
+		Column: u.Column,
+		Offset: u.Offset,
+	})
+}
+
+func (p Postfix) MarshalJSON() ([]byte, error) {
+	x, err := json.Marshal(p.X)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonPostfix{
+		Type:   "Postfix",
+		Op:     p.Op,
+		X:      x,
+		Line:   p.Line,
+		Column: p.Column,
+		Offset: p.Offset,
+		Size:   p.Size,
+	})
+}
+
+func (b Binary) MarshalJSON() ([]byte, error) {
+	x, err := json.Marshal(b.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := json.Marshal(b.Y)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonBinary{
+		Type:   "Binary",
+		Op:     b.Op,
+		X:      x,
+		Y:      y,
+		Line:   b.Line,
+		Column: b.Column,
+		Offset: b.Offset,
+	})
+}
+
+func (i ImpliedBinary) MarshalJSON() ([]byte, error) {
+	x, err := json.Marshal(i.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := json.Marshal(i.Y)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonImpliedBinary{
+		Type:   "ImpliedBinary",
+		Op:     i.Op,
+		X:      x,
+		Y:      y,
+		Line:   i.Line,
+		Column: i.Column,
+		Offset: i.Offset,
+	})
+}
+
+func (c Comparison) MarshalJSON() ([]byte, error) {
+	operands, err := marshalNodes(c.Operands)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonComparison{
+		Type:     "Comparison",
+		Ops:      c.Ops,
+		Operands: operands,
+		Line:     c.Line,
+		Column:   c.Column,
+		Offset:   c.Offset,
+	})
+}
+
+func (c Conditional) MarshalJSON() ([]byte, error) {
+	cond, err := json.Marshal(c.Cond)
+	if err != nil {
+		return nil, err
+	}
+	then, err := json.Marshal(c.Then)
+	if err != nil {
+		return nil, err
+	}
+	els, err := json.Marshal(c.Else)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonConditional{
+		Type:    "Conditional",
+		Cond:    cond,
+		Then:    then,
+		Else:    els,
+		Keyword: c.Keyword,
+		Line:    c.Line,
+		Column:  c.Column,
+		Offset:  c.Offset,
+		ThenPos: toPosition(c.ThenPos),
+		ElsePos: toPosition(c.ElsePos),
+	})
+}
+
+func (a Assign) MarshalJSON() ([]byte, error) {
+	name, err := json.Marshal(a.Name)
+	if err != nil {
+		return nil, err
+	}
+	value, err := json.Marshal(a.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonAssign{
+		Type:   "Assign",
+		Name:   name,
+		Value:  value,
+		Line:   a.Line,
+		Column: a.Column,
+		Offset: a.Offset,
+		Let:    toPosition(a.Let),
+	})
+}
+
+func (p Program) MarshalJSON() ([]byte, error) {
+	stmts, err := marshalNodes(p.Stmts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonProgram{
+		Type:  "Program",
+		Stmts: stmts,
+	})
+}
+
+func (l Lambda) MarshalJSON() ([]byte, error) {
+	params, err := marshalSymbols(l.Params)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(l.Body)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonLambda{
+		Type:   "Lambda",
+		Params: params,
+		Body:   body,
+		Line:   l.Line,
+		Column: l.Column,
+		Offset: l.Offset,
+		Lparen: toPosition(l.Lparen),
+		Rparen: toPosition(l.Rparen),
+	})
+}
+
+func (f FuncDef) MarshalJSON() ([]byte, error) {
+	name, err := json.Marshal(f.Name)
+	if err != nil {
+		return nil, err
+	}
+	params, err := marshalSymbols(f.Params)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(f.Body)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonFuncDef{
+		Type:   "FuncDef",
+		Name:   name,
+		Params: params,
+		Body:   body,
+		Line:   f.Line,
+		Column: f.Column,
+		Offset: f.Offset,
+		Lparen: toPosition(f.Lparen),
+		Rparen: toPosition(f.Rparen),
+	})
+}
+
+func (c Call) MarshalJSON() ([]byte, error) {
+	callee, err := json.Marshal(c.Callee)
+	if err != nil {
+		return nil, err
+	}
+	args, err := marshalNodes(c.Args)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonCall{
+		Type:   "Call",
+		Callee: callee,
+		Args:   args,
+		Line:   c.Line,
+		Column: c.Column,
+		Offset: c.Offset,
+		Rparen: toPosition(c.Rparen),
+	})
+}
+
+func (l List) MarshalJSON() ([]byte, error) {
+	elems, err := marshalNodes(l.Elems)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonList{
+		Type:   "List",
+		Elems:  elems,
+		Lbrack: toPosition(l.Lbrack),
+		Rbrack: toPosition(l.Rbrack),
+	})
+}
+
+func (m Matrix) MarshalJSON() ([]byte, error) {
+	rows := make([][]json.RawMessage, len(m.Rows))
+	for i, row := range m.Rows {
+		var err error
+		if rows[i], err = marshalNodes(row); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(jsonMatrix{
+		Type:   "Matrix",
+		Rows:   rows,
+		Lbrack: toPosition(m.Lbrack),
+		Rbrack: toPosition(m.Rbrack),
+	})
+}
+
+func (i Index) MarshalJSON() ([]byte, error) {
+	x, err := json.Marshal(i.X)
+	if err != nil {
+		return nil, err
+	}
+	indices, err := marshalNodes(i.Indices)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonIndex{
+		Type:    "Index",
+		X:       x,
+		Indices: indices,
+		Line:    i.Line,
+		Column:  i.Column,
+		Offset:  i.Offset,
+		Rbrack:  toPosition(i.Rbrack),
+	})
+}
+
+func (p Paren) MarshalJSON() ([]byte, error) {
+	x, err := json.Marshal(p.X)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonParen{
+		Type:   "Paren",
+		X:      x,
+		Lparen: toPosition(p.Lparen),
+		Rparen: toPosition(p.Rparen),
+	})
+}
+
+// Encodes each node of a slice.
+func marshalNodes(nodes []Node) ([]json.RawMessage, error) {
+	data := make([]json.RawMessage, len(nodes))
+	for i, n := range nodes {
+		var err error
+		if data[i], err = json.Marshal(n); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// Encodes each symbol of a parameter list.
+func marshalSymbols(params []Symbol) ([]json.RawMessage, error) {
+	data := make([]json.RawMessage, len(params))
+	for i, p := range params {
+		var err error
+		if data[i], err = json.Marshal(p); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// Decodes each symbol of a parameter list.
+func unmarshalSymbols(data []json.RawMessage) ([]Symbol, error) {
+	params := make([]Symbol, len(data))
+	for i, d := range data {
+		if err := params[i].UnmarshalJSON(d); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+// Decodes each node of a slice.
+func unmarshalNodes(data []json.RawMessage) ([]Node, error) {
+	nodes := make([]Node, len(data))
+	for i, d := range data {
+		var err error
+		if nodes[i], err = UnmarshalNode(d); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
+// Inputs the JSON encoding of any node and outputs that node as its
+// concrete type. The JSON "null" decodes to a nil Node.
+func UnmarshalNode(data []byte) (Node, error) {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil, nil
+	}
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+	switch head.Type {
+	case "Empty":
+		var n Empty
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Bad":
+		var n Bad
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Number":
+		var n Number
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Symbol":
+		var n Symbol
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Boolean":
+		var n Boolean
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "String":
+		var n String
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Unary":
+		var n Unary
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Postfix":
+		var n Postfix
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Binary":
+		var n Binary
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "ImpliedBinary":
+		var n ImpliedBinary
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Comparison":
+		var n Comparison
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Conditional":
+		var n Conditional
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Assign":
+		var n Assign
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Program":
+		var n Program
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Lambda":
+		var n Lambda
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "FuncDef":
+		var n FuncDef
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Call":
+		var n Call
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "List":
+		var n List
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Matrix":
+		var n Matrix
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Index":
+		var n Index
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "Paren":
+		var n Paren
+		err := n.UnmarshalJSON(data)
+		return n, err
+	case "":
+		return nil, fmt.Errorf("node has no type: %s", data)
+	default:
+		return nil, fmt.Errorf("unknown node type %q", head.Type)
+	}
+}
+
+// Checks the "type" discriminator of a node being decoded.
+func discriminate(want, got string) error {
+	if got != want {
+		return fmt.Errorf("cannot unmarshal node type %q into %s", got, want)
+	}
+	return nil
+}
+
+func (e *Empty) UnmarshalJSON(data []byte) error {
+	var j jsonEmpty
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	return discriminate("Empty", j.Type)
+}
+
+func (b *Bad) UnmarshalJSON(data []byte) error {
+	var j jsonBad
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Bad", j.Type); err != nil {
+		return err
+	}
+	*b = Bad{
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+		To:     fromPosition(j.To),
+	}
+	return nil
+}
+
+func (n *Number) UnmarshalJSON(data []byte) error {
+	var j jsonNumber
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Number", j.Type); err != nil {
+		return err
+	}
+	*n = Number{
+		Value:  j.Value,
+		Raw:    j.Raw,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+	}
+	return nil
+}
+
+func (s *Symbol) UnmarshalJSON(data []byte) error {
+	var j jsonSymbol
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Symbol", j.Type); err != nil {
+		return err
+	}
+	*s = Symbol{
+		Value:  j.Value,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+	}
+	return nil
+}
+
+func (b *Boolean) UnmarshalJSON(data []byte) error {
+	var j jsonBoolean
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Boolean", j.Type); err != nil {
+		return err
+	}
+	*b = Boolean{
+		Value:  j.Value,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+	}
+	return nil
+}
+
+func (s *String) UnmarshalJSON(data []byte) error {
+	var j jsonString
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("String", j.Type); err != nil {
+		return err
+	}
+	*s = String{
+		Value:  j.Value,
+		Raw:    j.Raw,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+	}
+	return nil
+}
+
+func (u *Unary) UnmarshalJSON(data []byte) error {
+	var j jsonUnary
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Unary", j.Type); err != nil {
+		return err
+	}
+	x, err := UnmarshalNode(j.X)
+	if err != nil {
+		return err
+	}
+	*u = Unary{
+		Op:     j.Op,
+		X:      x,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+	}
+	return nil
+}
+
+func (p *Postfix) UnmarshalJSON(data []byte) error {
+	var j jsonPostfix
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Postfix", j.Type); err != nil {
+		return err
+	}
+	x, err := UnmarshalNode(j.X)
+	if err != nil {
+		return err
+	}
+	*p = Postfix{
+		Op:     j.Op,
+		X:      x,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+		Size:   j.Size,
+	}
+	return nil
+}
+
+func (b *Binary) UnmarshalJSON(data []byte) error {
+	var j jsonBinary
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Binary", j.Type); err != nil {
+		return err
+	}
+	x, err := UnmarshalNode(j.X)
+	if err != nil {
+		return err
+	}
+	y, err := UnmarshalNode(j.Y)
+	if err != nil {
+		return err
+	}
+	*b = Binary{
+		Op:     j.Op,
+		X:      x,
+		Y:      y,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+	}
+	return nil
+}
+
+func (i *ImpliedBinary) UnmarshalJSON(data []byte) error {
+	var j jsonImpliedBinary
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("ImpliedBinary", j.Type); err != nil {
+		return err
+	}
+	x, err := UnmarshalNode(j.X)
+	if err != nil {
+		return err
+	}
+	y, err := UnmarshalNode(j.Y)
+	if err != nil {
+		return err
+	}
+	*i = ImpliedBinary{
+		Op:     j.Op,
+		X:      x,
+		Y:      y,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+	}
+	return nil
+}
+
+func (c *Comparison) UnmarshalJSON(data []byte) error {
+	var j jsonComparison
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Comparison", j.Type); err != nil {
+		return err
+	}
+	operands, err := unmarshalNodes(j.Operands)
+	if err != nil {
+		return err
+	}
+	if len(operands) != len(j.Ops)+1 {
+		return fmt.Errorf("comparison of %d operators has %d operands", len(j.Ops), len(operands))
+	}
+	*c = Comparison{
+		Ops:      j.Ops,
+		Operands: operands,
+		Line:     j.Line,
+		Column:   j.Column,
+		Offset:   j.Offset,
+	}
+	return nil
+}
+
+func (c *Conditional) UnmarshalJSON(data []byte) error {
+	var j jsonConditional
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Conditional", j.Type); err != nil {
+		return err
+	}
+	cond, err := UnmarshalNode(j.Cond)
+	if err != nil {
+		return err
+	}
+	then, err := UnmarshalNode(j.Then)
+	if err != nil {
+		return err
+	}
+	els, err := UnmarshalNode(j.Else)
+	if err != nil {
+		return err
+	}
+	*c = Conditional{
+		Cond:    cond,
+		Then:    then,
+		Else:    els,
+		Keyword: j.Keyword,
+		Line:    j.Line,
+		Column:  j.Column,
+		Offset:  j.Offset,
+		ThenPos: fromPosition(j.ThenPos),
+		ElsePos: fromPosition(j.ElsePos),
+	}
+	return nil
+}
+
+func (a *Assign) UnmarshalJSON(data []byte) error {
+	var j jsonAssign
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Assign", j.Type); err != nil {
+		return err
+	}
+	var name Symbol
+	if err := name.UnmarshalJSON(j.Name); err != nil {
+		return err
+	}
+	value, err := UnmarshalNode(j.Value)
+	if err != nil {
+		return err
+	}
+	*a = Assign{
+		Name:   name,
+		Value:  value,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+		Let:    fromPosition(j.Let),
+	}
+	return nil
+}
+
+func (p *Program) UnmarshalJSON(data []byte) error {
+	var j jsonProgram
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Program", j.Type); err != nil {
+		return err
+	}
+	stmts, err := unmarshalNodes(j.Stmts)
+	if err != nil {
+		return err
+	}
+	*p = Program{Stmts: stmts}
+	return nil
+}
+
+func (l *Lambda) UnmarshalJSON(data []byte) error {
+	var j jsonLambda
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Lambda", j.Type); err != nil {
+		return err
+	}
+	params, err := unmarshalSymbols(j.Params)
+	if err != nil {
+		return err
+	}
+	body, err := UnmarshalNode(j.Body)
+	if err != nil {
+		return err
+	}
+	*l = Lambda{
+		Params: params,
+		Body:   body,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+		Lparen: fromPosition(j.Lparen),
+		Rparen: fromPosition(j.Rparen),
+	}
+	return nil
+}
+
+func (f *FuncDef) UnmarshalJSON(data []byte) error {
+	var j jsonFuncDef
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("FuncDef", j.Type); err != nil {
+		return err
+	}
+	var name Symbol
+	if err := name.UnmarshalJSON(j.Name); err != nil {
+		return err
+	}
+	params, err := unmarshalSymbols(j.Params)
+	if err != nil {
+		return err
+	}
+	body, err := UnmarshalNode(j.Body)
+	if err != nil {
+		return err
+	}
+	*f = FuncDef{
+		Name:   name,
+		Params: params,
+		Body:   body,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+		Lparen: fromPosition(j.Lparen),
+		Rparen: fromPosition(j.Rparen),
+	}
+	return nil
+}
+
+func (c *Call) UnmarshalJSON(data []byte) error {
+	var j jsonCall
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Call", j.Type); err != nil {
+		return err
+	}
+	callee, err := UnmarshalNode(j.Callee)
+	if err != nil {
+		return err
+	}
+	args, err := unmarshalNodes(j.Args)
+	if err != nil {
+		return err
+	}
+	*c = Call{
+		Callee: callee,
+		Args:   args,
+		Line:   j.Line,
+		Column: j.Column,
+		Offset: j.Offset,
+		Rparen: fromPosition(j.Rparen),
+	}
+	return nil
+}
+
+func (l *List) UnmarshalJSON(data []byte) error {
+	var j jsonList
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("List", j.Type); err != nil {
+		return err
+	}
+	elems, err := unmarshalNodes(j.Elems)
+	if err != nil {
+		return err
+	}
+	*l = List{
+		Elems:  elems,
+		Lbrack: fromPosition(j.Lbrack),
+		Rbrack: fromPosition(j.Rbrack),
+	}
+	return nil
+}
+
+func (m *Matrix) UnmarshalJSON(data []byte) error {
+	var j jsonMatrix
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Matrix", j.Type); err != nil {
+		return err
+	}
+	rows := make([][]Node, len(j.Rows))
+	for i, row := range j.Rows {
+		var err error
+		if rows[i], err = unmarshalNodes(row); err != nil {
+			return err
+		}
+	}
+	*m = Matrix{
+		Rows:   rows,
+		Lbrack: fromPosition(j.Lbrack),
+		Rbrack: fromPosition(j.Rbrack),
+	}
+	return nil
+}
+
+func (i *Index) UnmarshalJSON(data []byte) error {
+	var j jsonIndex
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Index", j.Type); err != nil {
+		return err
+	}
+	x, err := UnmarshalNode(j.X)
+	if err != nil {
+		return err
+	}
+	indices, err := unmarshalNodes(j.Indices)
+	if err != nil {
+		return err
+	}
+	*i = Index{
+		X:       x,
+		Indices: indices,
+		Line:    j.Line,
+		Column:  j.Column,
+		Offset:  j.Offset,
+		Rbrack:  fromPosition(j.Rbrack),
+	}
+	return nil
+}
+
+func (p *Paren) UnmarshalJSON(data []byte) error {
+	var j jsonParen
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if err := discriminate("Paren", j.Type); err != nil {
+		return err
+	}
+	x, err := UnmarshalNode(j.X)
+	if err != nil {
+		return err
+	}
+	*p = Paren{
+		X:      x,
+		Lparen: fromPosition(j.Lparen),
+		Rparen: fromPosition(j.Rparen),
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, text := range fixtures {
+		expect, err := Parse(text)
+		if err != nil {
+			t.Fatalf("TestJSONRoundTrip failed for %q. Got: %s", text, err)
+		}
+		data, err := json.Marshal(expect)
+		if err != nil {
+			t.Fatalf("TestJSONRoundTrip failed for %q. Got: %s", text, err)
+		}
+		result, err := UnmarshalNode(data)
+		if err != nil {
+			t.Errorf("TestJSONRoundTrip failed for %q. Expected: %s, Got: %s", text, expect, err)
+			continue
+		}
+		if !Equal(expect, result) {
+			t.Errorf("TestJSONRoundTrip failed for %q. Expected: %s, Got: %s", text, expect, result)
+		}
+	}
+}
+
+func TestJSONProgram(t *testing.T) {
+	expect, err := ParseProgram("let r = 3\narea = pi r^2; area")
+	if err != nil {
+		t.Fatalf("TestJSONProgram failed. Got: %s", err)
+	}
+	data, err := json.Marshal(expect)
+	if err != nil {
+		t.Fatalf("TestJSONProgram failed. Got: %s", err)
+	}
+	result, err := UnmarshalNode(data)
+	if err != nil || !Equal(expect, result) {
+		t.Errorf("TestJSONProgram failed. Expected: %s, Got: %v %v", expect, result, err)
+	}
+}
+
+func TestJSONBad(t *testing.T) {
+	expect, _ := ParseAll("1 + (* 2)")
+	data, err := json.Marshal(expect)
+	if err != nil {
+		t.Fatalf("TestJSONBad failed. Got: %s", err)
+	}
+	result, err := UnmarshalNode(data)
+	if err != nil || !Equal(expect, result) {
+		t.Errorf("TestJSONBad failed. Expected: %s, Got: %v %v", expect, result, err)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	node, _ := Parse("-f(x)")
+	expect := `{"type":"Unary","op":"-",` +
+		`"x":{"type":"Call",` +
+		`"callee":{"type":"Symbol","value":"f","line":1,"column":2,"offset":1},` +
+		`"args":[{"type":"Symbol","value":"x","line":1,"column":4,"offset":3}],` +
+		`"line":1,"column":3,"offset":2,` +
+		`"rparen":{"line":1,"column":5,"offset":4}},` +
+		`"line":1,"column":1,"offset":0}`
+	data, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("TestJSONFormat failed. Got: %s", err)
+	}
+	if string(data) != expect {
+		t.Errorf("TestJSONFormat failed. Expected: %s, Got: %s", expect, data)
+	}
+}
+
+func TestJSONConcrete(t *testing.T) {
+	expect, _ := Parse(fixtures[0])
+	data, _ := json.Marshal(expect)
+	var result Binary
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("TestJSONConcrete failed. Got: %s", err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestJSONConcrete failed. Expected: %s, Got: %s", expect, result)
+	}
+	var wrong Call
+	if err := json.Unmarshal(data, &wrong); err == nil {
+		t.Errorf("TestJSONConcrete failed. Expected: error, Got: %s", wrong)
+	}
+}
+
+func TestUnmarshalNodeErrors(t *testing.T) {
+	tests := []string{
+		`{"value":7}`,
+		`{"type":"Tensor"}`,
+		`{"type":"Unary","op":"-","x":{"type":"Tensor"}}`,
+		`{"type":"Call","callee":{"type":"Symbol","value":"f"},"args":[{}]}`,
+		`[1, 2]`,
+		`{"type":"Number","value":"7"}`,
+	}
+	for _, test := range tests {
+		if result, err := UnmarshalNode([]byte(test)); err == nil {
+			t.Errorf("TestUnmarshalNodeErrors failed for %s. Expected: error, Got: %s", test, result)
+		}
+	}
+	if result, err := UnmarshalNode([]byte("null")); err != nil || result != nil {
+		t.Errorf("TestUnmarshalNodeErrors failed for null. Expected: nil, Got: %v %v", result, err)
+	}
+}
@@ -1,71 +1,34 @@
 package parser
 
 import (
-	"fmt"
 	"testing"
 )
 
-// Recursively tests for structural equality between nodes.
-func equal(n, m Node) bool {
-	switch n := n.(type) {
-	case Empty:
-		m, ok := m.(Empty)
-		if !ok {
-			return false
-		}
-		return n == m
-	case Number:
-		m, ok := m.(Number)
-		if !ok {
-			return false
-		}
-		return n == m
-	case Symbol:
-		m, ok := m.(Symbol)
-		if !ok {
-			return false
-		}
-		return n == m
-	case Unary:
-		m, ok := m.(Unary)
-		if !ok {
-			return false
-		}
-		return n.Op == m.Op && equal(n.X, m.X) && n.Line == m.Line && n.Column == m.Column
-	case Binary:
-		m, ok := m.(Binary)
-		if !ok {
-			return false
-		}
-		return n.Op == m.Op && equal(n.X, m.X) && equal(n.Y, m.Y) && n.Line == m.Line && n.Column == m.Column
-	case ImpliedBinary:
-		m, ok := m.(ImpliedBinary)
-		if !ok {
-			return false
-		}
-		return n.Op == m.Op && equal(n.X, m.X) && equal(n.Y, m.Y)
-	case Call:
-		m, ok := m.(Call)
-		if !ok {
-			return false
-		}
-		if !equal(n.Callee, m.Callee) {
-			return false
-		}
-		// Since the default value for an empty "Args" is an empty slice,
-		// checking for length of each slice will not cause a panic.
-		if len(n.Args) != len(m.Args) {
-			return false
-		}
-		for i := range n.Args {
-			if !equal(n.Args[i], m.Args[i]) {
-				return false
-			}
-		}
-		return n.Line == m.Line && n.Column == m.Column
-	default:
-		return false
-	}
+// Source texts of every fixture in this file that parses. Tests of
+// other features reuse them as inputs.
+var fixtures = []string{
+	"1 + 2 * 3",
+	"",
+	"\r\n   ",
+	"wyvern ^ 11",
+	"7 + 4 = 11",
+	"1 + 2 + 3",
+	"((1 + (2)))",
+	"--7",
+	"7--7",
+	"1 ^ 2 ^ 3",
+	"square(5) + 2",
+	"random()",
+	"7x",
+	"1 × 2 ÷ 3",
+	"0 ≤ x < 10",
+	"true and not x",
+	"f'(x) + 3!%",
+	"a ? b : if c then d else e",
+	"f(x, y) := (z) -> x ↦ (() -> y)(z)",
+	"k(1)(2, 3)",
+	"[1, [2 3]] + [1 2; 3 4][1, :]",
+	`name = "Ada" and contains('it\'s', "\u00e9")`,
 }
 
 func TestBasic(t *testing.T) {
@@ -74,6 +37,7 @@ func TestBasic(t *testing.T) {
 		Op: "+",
 		X: Number{
 			Value:  1.0,
+			Raw:    "1",
 			Line:   1,
 			Column: 1,
 		},
@@ -81,11 +45,13 @@ func TestBasic(t *testing.T) {
 			Op: "*",
 			X: Number{
 				Value:  2.0,
+				Raw:    "2",
 				Line:   1,
 				Column: 5,
 			},
 			Y: Number{
 				Value:  3.0,
+				Raw:    "3",
 				Line:   1,
 				Column: 9,
 			},
@@ -99,7 +65,7 @@ func TestBasic(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestBasic failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestBasic failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -138,14 +104,14 @@ func TestEmpty(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestEmpty (1) failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestEmpty (1) failed. Expected: %s, Got: %s", expect, result)
 	}
 	text = "\r\n   "
 	if err != nil {
 		t.Errorf("TestEmpty (2) failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestEmpty (2) failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -161,6 +127,7 @@ func TestSymbol(t *testing.T) {
 		},
 		Y: Number{
 			Value:  11.0,
+			Raw:    "11",
 			Line:   1,
 			Column: 10,
 		},
@@ -171,7 +138,7 @@ func TestSymbol(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestSymbol failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestSymbol failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -184,11 +151,13 @@ func TestEqual(t *testing.T) {
 			Op: "+",
 			X: Number{
 				Value:  7.0,
+				Raw:    "7",
 				Line:   1,
 				Column: 1,
 			},
 			Y: Number{
 				Value:  4.0,
+				Raw:    "4",
 				Line:   1,
 				Column: 5,
 			},
@@ -197,6 +166,7 @@ func TestEqual(t *testing.T) {
 		},
 		Y: Number{
 			Value:  11.0,
+			Raw:    "11",
 			Line:   1,
 			Column: 9,
 		},
@@ -207,7 +177,7 @@ func TestEqual(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestEqual failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestEqual failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -220,11 +190,13 @@ func TestLeftAssociative(t *testing.T) {
 			Op: "+",
 			X: Number{
 				Value:  1.0,
+				Raw:    "1",
 				Line:   1,
 				Column: 1,
 			},
 			Y: Number{
 				Value:  2.0,
+				Raw:    "2",
 				Line:   1,
 				Column: 5,
 			},
@@ -233,6 +205,7 @@ func TestLeftAssociative(t *testing.T) {
 		},
 		Y: Number{
 			Value:  3.0,
+			Raw:    "3",
 			Line:   1,
 			Column: 9,
 		},
@@ -243,33 +216,71 @@ func TestLeftAssociative(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestLeftAssociative failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestLeftAssociative failed. Expected: %s, Got: %s", expect, result)
 	}
 }
 
 func TestParens(t *testing.T) {
 	text := "((1 + (2)))"
-	expect := Binary{
-		Op: "+",
-		X: Number{
-			Value:  1.0,
+	expect := Paren{
+		X: Paren{
+			X: Binary{
+				Op: "+",
+				X: Number{
+					Value:  1.0,
+					Raw:    "1",
+					Line:   1,
+					Column: 3,
+				},
+				Y: Paren{
+					X: Number{
+						Value:  2.0,
+						Raw:    "2",
+						Line:   1,
+						Column: 8,
+					},
+					Lparen: Position{
+						Offset: 6,
+						Line:   1,
+						Column: 7,
+					},
+					Rparen: Position{
+						Offset: 8,
+						Line:   1,
+						Column: 9,
+					},
+				},
+				Line:   1,
+				Column: 5,
+			},
+			Lparen: Position{
+				Offset: 1,
+				Line:   1,
+				Column: 2,
+			},
+			Rparen: Position{
+				Offset: 9,
+				Line:   1,
+				Column: 10,
+			},
+		},
+		Lparen: Position{
+			Offset: 0,
 			Line:   1,
-			Column: 3,
+			Column: 1,
 		},
-		Y: Number{
-			Value:  2.0,
+		Rparen: Position{
+			Offset: 10,
 			Line:   1,
-			Column: 8,
+			Column: 11,
 		},
-		Line:   1,
-		Column: 5,
 	}
 	result, err := Parse(text)
 	if err != nil {
 		t.Errorf("TestParens failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestParens failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -291,6 +302,7 @@ func TestUnary(t *testing.T) {
 			Op: "-",
 			X: Number{
 				Value:  7.0,
+				Raw:    "7",
 				Line:   1,
 				Column: 3,
 			},
@@ -304,7 +316,7 @@ func TestUnary(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestUnary failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestUnary failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -315,6 +327,7 @@ func TestMinus(t *testing.T) {
 		Op: "-",
 		X: Number{
 			Value:  7.0,
+			Raw:    "7",
 			Line:   1,
 			Column: 1,
 		},
@@ -322,6 +335,7 @@ func TestMinus(t *testing.T) {
 			Op: "-",
 			X: Number{
 				Value:  7.0,
+				Raw:    "7",
 				Line:   1,
 				Column: 4,
 			},
@@ -335,7 +349,7 @@ func TestMinus(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestMinus failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestMinus failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -346,6 +360,7 @@ func TestExponent(t *testing.T) {
 		Op: "^",
 		X: Number{
 			Value:  1.0,
+			Raw:    "1",
 			Line:   1,
 			Column: 1,
 		},
@@ -353,11 +368,13 @@ func TestExponent(t *testing.T) {
 			Op: "^",
 			X: Number{
 				Value:  2.0,
+				Raw:    "2",
 				Line:   1,
 				Column: 5,
 			},
 			Y: Number{
 				Value:  3.0,
+				Raw:    "3",
 				Line:   1,
 				Column: 9,
 			},
@@ -371,7 +388,7 @@ func TestExponent(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestExponent failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestExponent failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -392,15 +409,22 @@ func TestCall(t *testing.T) {
 			Args: []Node{
 				Number{
 					Value:  5.0,
+					Raw:    "5",
 					Line:   1,
 					Column: 8,
 				},
 			},
 			Line:   1,
 			Column: 7,
+			Rparen: Position{
+				Offset: 8,
+				Line:   1,
+				Column: 9,
+			},
 		},
 		Y: Number{
 			Value:  2.0,
+			Raw:    "2",
 			Line:   1,
 			Column: 13,
 		},
@@ -411,7 +435,7 @@ func TestCall(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestCall (1) failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestCall (1) failed. Expected: %s, Got: %s", expect, result)
 	}
 
@@ -425,12 +449,17 @@ func TestCall(t *testing.T) {
 		Args:   make([]Node, 0),
 		Line:   1,
 		Column: 7,
+		Rparen: Position{
+			Offset: 7,
+			Line:   1,
+			Column: 8,
+		},
 	}
 	result, err = Parse(text)
 	if err != nil {
 		t.Errorf("TestCall (2) failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestCall (2) failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -450,6 +479,7 @@ func TestImpliedBinary(t *testing.T) {
 		Op: "*",
 		X: Number{
 			Value:  7.0,
+			Raw:    "7",
 			Line:   1,
 			Column: 1,
 		},
@@ -458,12 +488,14 @@ func TestImpliedBinary(t *testing.T) {
 			Line:   1,
 			Column: 2,
 		},
+		Line:   1,
+		Column: 2,
 	}
 	result, err := Parse(text)
 	if err != nil {
 		t.Errorf("TestImpliedBinary failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestImpliedBinary failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -476,11 +508,13 @@ func TestAltOperators(t *testing.T) {
 			Op: "*",
 			X: Number{
 				Value:  1.0,
+				Raw:    "1",
 				Line:   1,
 				Column: 1,
 			},
 			Y: Number{
 				Value:  2.0,
+				Raw:    "2",
 				Line:   1,
 				Column: 5,
 			},
@@ -489,6 +523,7 @@ func TestAltOperators(t *testing.T) {
 		},
 		Y: Number{
 			Value:  3.0,
+			Raw:    "3",
 			Line:   1,
 			Column: 9,
 		},
@@ -499,7 +534,7 @@ func TestAltOperators(t *testing.T) {
 	if err != nil {
 		t.Errorf("TestAltOperators failed. Expected: %s, Got: %s", expect, err)
 	}
-	if !equal(expect, result) {
+	if !Equal(expect, result) {
 		t.Errorf("TestAltOperators failed. Expected: %s, Got: %s", expect, result)
 	}
 }
@@ -512,3 +547,658 @@ func TestUnusedTokens(t *testing.T) {
 		t.Errorf(msg, result)
 	}
 }
+
+func TestComparison(t *testing.T) {
+	var text string
+	var expect Node
+
+	text = "x + 1 ≥ y"
+	expect = Binary{
+		Op: ">=",
+		X: Binary{
+			Op: "+",
+			X: Symbol{
+				Value:  "x",
+				Line:   1,
+				Column: 1,
+			},
+			Y: Number{
+				Value:  1.0,
+				Raw:    "1",
+				Line:   1,
+				Column: 5,
+			},
+			Line:   1,
+			Column: 3,
+		},
+		Y: Symbol{
+			Value:  "y",
+			Line:   1,
+			Column: 9,
+		},
+		Line:   1,
+		Column: 7,
+	}
+	result, err := Parse(text)
+	if err != nil {
+		t.Errorf("TestComparison (1) failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestComparison (1) failed. Expected: %s, Got: %s", expect, result)
+	}
+
+	text = "0 ≤ x < 10"
+	expect = Comparison{
+		Ops: []string{"<=", "<"},
+		Operands: []Node{
+			Number{
+				Value:  0.0,
+				Raw:    "0",
+				Line:   1,
+				Column: 1,
+			},
+			Symbol{
+				Value:  "x",
+				Line:   1,
+				Column: 5,
+			},
+			Number{
+				Value:  10.0,
+				Raw:    "10",
+				Line:   1,
+				Column: 9,
+			},
+		},
+		Line:   1,
+		Column: 3,
+	}
+	result, err = Parse(text)
+	if err != nil {
+		t.Errorf("TestComparison (2) failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestComparison (2) failed. Expected: %s, Got: %s", expect, result)
+	}
+	if span := text[result.Pos().Offset:result.End().Offset]; span != text {
+		t.Errorf("TestComparison (2) failed. Expected: %q, Got: %q", text, span)
+	}
+
+	// Parentheses break a chain.
+	result, err = Parse("(a < b) < c")
+	if b, ok := result.(Binary); err != nil || !ok || b.Op != "<" {
+		t.Errorf("TestComparison (3) failed. Expected: Binary, Got: %v %v", result, err)
+	}
+}
+
+func TestLogical(t *testing.T) {
+	text := "true or ¬x"
+	expect := Binary{
+		Op: "or",
+		X: Boolean{
+			Value:  true,
+			Line:   1,
+			Column: 1,
+		},
+		Y: Unary{
+			Op: "not",
+			X: Symbol{
+				Value:  "x",
+				Line:   1,
+				Column: 10,
+			},
+			Line:   1,
+			Column: 9,
+		},
+		Line:   1,
+		Column: 6,
+	}
+	result, err := Parse(text)
+	if err != nil {
+		t.Errorf("TestLogical failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestLogical failed. Expected: %s, Got: %s", expect, result)
+	}
+	// "or" binds loosest, then "and", then "not", then comparisons.
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{"a or b and c", "a or (b and c)"},
+		{"a and b or c", "(a and b) or c"},
+		{"not a and b", "(not a) and b"},
+		{"not a = b", "not (a = b)"},
+		{"a && b || !c", "(a and b) or (not c)"},
+		{"x > 1 ∧ x < 5 ∨ false", "((x > 1) and (x < 5)) or false"},
+	}
+	for _, test := range tests {
+		n, err := Parse(test.text)
+		if err != nil {
+			t.Errorf("TestLogical failed for %q. Got: %s", test.text, err)
+			continue
+		}
+		m, _ := Parse(test.expect)
+		if !Equal(unparen(n), unparen(m), IgnorePositions()) {
+			t.Errorf("TestLogical failed for %q. Expected: %s, Got: %s", test.text, test.expect, Print(n))
+		}
+	}
+}
+
+func TestPostfixOperators(t *testing.T) {
+	text := "2^3!"
+	expect := Binary{
+		Op: "^",
+		X: Number{
+			Value:  2,
+			Raw:    "2",
+			Line:   1,
+			Column: 1,
+		},
+		Y: Postfix{
+			Op: "!",
+			X: Number{
+				Value:  3,
+				Raw:    "3",
+				Line:   1,
+				Column: 3,
+			},
+			Line:   1,
+			Column: 4,
+		},
+		Line:   1,
+		Column: 2,
+	}
+	result, err := Parse(text)
+	if err != nil {
+		t.Errorf("TestPostfixOperators failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestPostfixOperators failed. Expected: %s, Got: %s", expect, result)
+	}
+	if pos, end := result.Pos(), result.End(); pos.Column != 1 || end.Column != 5 {
+		t.Errorf("TestPostfixOperators failed. Expected: columns 1 to 5, Got: %s to %s", pos, end)
+	}
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{"3!x", "(3!)x"},
+		{"2x!", "2(x!)"},
+		{"-3!", "-(3!)"},
+		{"50% * 2", "(50%) * 2"},
+		{"n!!", "(n!)!"},
+		{"!x!", "not (x!)"},
+	}
+	for _, test := range tests {
+		n, err := Parse(test.text)
+		if err != nil {
+			t.Errorf("TestPostfixOperators failed for %q. Got: %s", test.text, err)
+			continue
+		}
+		m, _ := Parse(test.expect)
+		if !Equal(unparen(n), unparen(m), IgnorePositions()) {
+			t.Errorf("TestPostfixOperators failed for %q. Expected: %s, Got: %s", test.text, test.expect, Print(n))
+		}
+	}
+	// A primed symbol is callable.
+	result, err = Parse("f''(x)")
+	call, ok := result.(Call)
+	if err != nil || !ok {
+		t.Fatalf("TestPostfixOperators failed. Expected: Call, Got: %v %v", result, err)
+	}
+	if p, ok := call.Callee.(Postfix); !ok || p.Op != "'" {
+		t.Errorf("TestPostfixOperators failed. Expected: Postfix callee, Got: %s", call.Callee)
+	}
+	if _, err := Parse("x%(y)"); err != nil {
+		t.Errorf("TestPostfixOperators failed for %q. Expected: implied multiplication, Got: %s", "x%(y)", err)
+	}
+	if result, err := Parse("(x!)'(y)"); err != nil {
+		t.Errorf("TestPostfixOperators failed for %q. Expected: Call, Got: %v %v", "(x!)'(y)", result, err)
+	}
+}
+
+func TestConditional(t *testing.T) {
+	text := "a ? b : c"
+	expect := Conditional{
+		Cond: Symbol{
+			Value:  "a",
+			Line:   1,
+			Column: 1,
+		},
+		Then: Symbol{
+			Value:  "b",
+			Line:   1,
+			Column: 5,
+			Offset: 4,
+		},
+		Else: Symbol{
+			Value:  "c",
+			Line:   1,
+			Column: 9,
+			Offset: 8,
+		},
+		Line:    1,
+		Column:  3,
+		Offset:  2,
+		ElsePos: Position{Offset: 6, Line: 1, Column: 7},
+	}
+	result, err := Parse(text)
+	if err != nil {
+		t.Errorf("TestConditional failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestConditional failed. Expected: %s, Got: %s", expect, result)
+	}
+	// "if" spans from its keyword to the end of its "else" branch.
+	result, _ = Parse("1 + if a then b else c")
+	c := result.(Binary).Y.(Conditional)
+	if c.Pos().Column != 5 || c.End().Column != 23 || c.ThenPos.Column != 10 || c.ElsePos.Column != 17 {
+		t.Errorf("TestConditional failed. Got: %s to %s, then: %s, else: %s", c.Pos(), c.End(), c.ThenPos, c.ElsePos)
+	}
+	// Both forms nest to the right.
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{"a ? b : c ? d : e", "a ? b : (c ? d : e)"},
+		{"a ? b ? c : d : e", "a ? (b ? c : d) : e"},
+		{"a or b ? c : d", "(a or b) ? c : d"},
+		{"a ? b : c + 1", "a ? b : (c + 1)"},
+		{"if a then b else if c then d else e", "if a then b else (if c then d else e)"},
+		{"if a then b else c ? d : e", "if a then b else (c ? d : e)"},
+		{"x > 0 ? x : -x", "(x > 0) ? x : (-x)"},
+	}
+	for _, test := range tests {
+		n, err := Parse(test.text)
+		if err != nil {
+			t.Errorf("TestConditional failed for %q. Got: %s", test.text, err)
+			continue
+		}
+		m, _ := Parse(test.expect)
+		if !Equal(unparen(n), unparen(m), IgnorePositions()) {
+			t.Errorf("TestConditional failed for %q. Expected: %s, Got: %s", test.text, test.expect, Print(n))
+		}
+	}
+	for _, text := range []string{"a ? b", "if a b else c", "if a then b", "a ? b : "} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("TestConditional failed. Expected: error for %q", text)
+		}
+	}
+	_, err = Parse("a ? b")
+	if e, ok := err.(*Error); !ok || e.Kind != MissingDelimiter || e.Pos.Column != 3 {
+		t.Errorf("TestConditional failed. Expected: missing delimiter at column 3, Got: %v", err)
+	}
+}
+
+func TestProgram(t *testing.T) {
+	text := "let r = 3\narea = r^2; area"
+	expect := Program{
+		Stmts: []Node{
+			Assign{
+				Name: Symbol{
+					Value:  "r",
+					Line:   1,
+					Column: 5,
+					Offset: 4,
+				},
+				Value: Number{
+					Value:  3,
+					Raw:    "3",
+					Line:   1,
+					Column: 9,
+					Offset: 8,
+				},
+				Line:   1,
+				Column: 7,
+				Offset: 6,
+				Let:    Position{Offset: 0, Line: 1, Column: 1},
+			},
+			Assign{
+				Name: Symbol{
+					Value:  "area",
+					Line:   2,
+					Column: 1,
+					Offset: 10,
+				},
+				Value: Binary{
+					Op: "^",
+					X: Symbol{
+						Value:  "r",
+						Line:   2,
+						Column: 8,
+						Offset: 17,
+					},
+					Y: Number{
+						Value:  2,
+						Raw:    "2",
+						Line:   2,
+						Column: 10,
+						Offset: 19,
+					},
+					Line:   2,
+					Column: 9,
+					Offset: 18,
+				},
+				Line:   2,
+				Column: 6,
+				Offset: 15,
+			},
+			Symbol{
+				Value:  "area",
+				Line:   2,
+				Column: 13,
+				Offset: 22,
+			},
+		},
+	}
+	result, err := ParseProgram(text)
+	if err != nil {
+		t.Fatalf("TestProgram failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestProgram failed. Expected: %s, Got: %s", expect, result)
+	}
+	tests := []struct {
+		text  string
+		stmts int
+	}{
+		{"", 0},
+		{";\n;", 0},
+		{"1 +\n2", 1},
+		{"(1\n+ 2)", 1},
+		{"x\n-y", 2},
+		{"x > 0\n  ? 1\n  : 2\ny", 2},
+		{"if c\nthen a\nelse b", 1},
+		{"2\nx", 2},
+		{"a = 1;\n\nb = 2;", 2},
+	}
+	for _, test := range tests {
+		result, err := ParseProgram(test.text)
+		if err != nil {
+			t.Errorf("TestProgram failed for %q. Got: %s", test.text, err)
+			continue
+		}
+		if n := len(result.(Program).Stmts); n != test.stmts {
+			t.Errorf("TestProgram failed for %q. Expected: %d statements, Got: %s", test.text, test.stmts, result)
+		}
+	}
+	// Only a name may be assigned. Otherwise "=" tests equality.
+	result, _ = ParseProgram("(x) = 1; x + 1 = 2")
+	for _, stmt := range result.(Program).Stmts {
+		if _, ok := stmt.(Assign); ok {
+			t.Errorf("TestProgram failed. Expected: equality, Got: %s", stmt)
+		}
+	}
+	errs := []struct {
+		text string
+		kind ErrorKind
+	}{
+		{"1 2", MissingDelimiter},
+		{"let 2 = x", InvalidBinding},
+		{"let x 2", MissingDelimiter},
+		{"let x = ", UnexpectedEOF},
+	}
+	for _, test := range errs {
+		_, err := ParseProgram(test.text)
+		if e, ok := err.(*Error); !ok || e.Kind != test.kind {
+			t.Errorf("TestProgram failed for %q. Expected: %s, Got: %v", test.text, test.kind, err)
+		}
+	}
+	// Outside a program, "let" and ";" are not expressions.
+	if _, err := Parse("x = 1; x"); err == nil {
+		t.Errorf("TestProgram failed. Expected: error for %q", "x = 1; x")
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	text := "f(x) := y ↦ x"
+	expect := FuncDef{
+		Name: Symbol{
+			Value:  "f",
+			Line:   1,
+			Column: 1,
+		},
+		Params: []Symbol{
+			{
+				Value:  "x",
+				Line:   1,
+				Column: 3,
+				Offset: 2,
+			},
+		},
+		Body: Lambda{
+			Params: []Symbol{
+				{
+					Value:  "y",
+					Line:   1,
+					Column: 9,
+					Offset: 8,
+				},
+			},
+			Body: Symbol{
+				Value:  "x",
+				Line:   1,
+				Column: 13,
+				Offset: 14,
+			},
+			Line:   1,
+			Column: 11,
+			Offset: 10,
+		},
+		Line:   1,
+		Column: 6,
+		Offset: 5,
+		Lparen: Position{Offset: 1, Line: 1, Column: 2},
+		Rparen: Position{Offset: 3, Line: 1, Column: 4},
+	}
+	result, err := Parse(text)
+	if err != nil {
+		t.Fatalf("TestFunctions failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestFunctions failed. Expected: %s, Got: %s", expect, result)
+	}
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{"(x, y) -> x + y", "(x, y) -> (x + y)"},
+		{"() -> 1", "() -> (1)"},
+		{"x -> y -> x y", "x -> (y -> (x y))"},
+		{"x -> x ? 1 : 2", "x -> (x ? 1 : 2)"},
+		{"2 + x -> x + 1", "2 + (x -> (x + 1))"},
+		{"f(x -> 2x, 1)", "f((x -> 2x), 1)"},
+		{"(x -> 2x)(3)", "(x -> 2x)(3)"},
+		{"g(x, y) := x ^ 2 + y", "g(x, y) := (x ^ 2 + y)"},
+	}
+	for _, test := range tests {
+		n, err := Parse(test.text)
+		if err != nil {
+			t.Errorf("TestFunctions failed for %q. Got: %s", test.text, err)
+			continue
+		}
+		m, _ := Parse(test.expect)
+		if !Equal(unparen(n), unparen(m), IgnorePositions()) {
+			t.Errorf("TestFunctions failed for %q. Expected: %s, Got: %s", test.text, test.expect, Print(n))
+		}
+	}
+	// A parenthesized lambda is called, unlike other groupings.
+	result, _ = Parse("(x -> 2x)(3)")
+	if _, ok := result.(Call); !ok {
+		t.Errorf("TestFunctions failed. Expected: Call, Got: %s", result)
+	}
+	// So is the result of a call, if directly followed by a parenthesis.
+	result, _ = Parse("k(1)(2, 3)")
+	if c, ok := result.(Call); !ok || len(c.Args) != 2 || Print(c.Callee) != "k(1)" {
+		t.Errorf("TestFunctions failed. Expected: k(1)(2, 3), Got: %s", result)
+	}
+	result, _ = Parse("k(1) (2)")
+	if _, ok := result.(ImpliedBinary); !ok {
+		t.Errorf("TestFunctions failed. Expected: ImpliedBinary, Got: %s", result)
+	}
+	errs := []struct {
+		text string
+		kind ErrorKind
+	}{
+		{"(x, 2) -> x", InvalidBinding},
+		{"(x, y)", MissingDelimiter},
+		{"(x, x) -> x", InvalidBinding},
+		{"x + 1 := 2", InvalidBinding},
+		{"f(2) := 2", InvalidBinding},
+		{"x -> ", UnexpectedEOF},
+	}
+	for _, test := range errs {
+		_, err := Parse(test.text)
+		if e, ok := err.(*Error); !ok || e.Kind != test.kind {
+			t.Errorf("TestFunctions failed for %q. Expected: %s, Got: %v", test.text, test.kind, err)
+		}
+	}
+	// Parameter errors span the offending parameter.
+	_, err = Parse("f(x, 2y) := 1")
+	if e, ok := err.(*Error); !ok || e.Pos.Column != 6 || e.End.Column != 8 {
+		t.Errorf("TestFunctions failed. Expected: error at columns 6 to 8, Got: %v", err)
+	}
+	_, err = Parse("(1, 2) -> 1")
+	if e, ok := err.(*Error); !ok || e.Kind != InvalidBinding || e.Pos.Column != 2 {
+		t.Errorf("TestFunctions failed. Expected: invalid binding at column 2, Got: %v", err)
+	}
+}
+
+func TestBrackets(t *testing.T) {
+	text := "v[2, :]"
+	expect := Index{
+		X: Symbol{
+			Value:  "v",
+			Line:   1,
+			Column: 1,
+		},
+		Indices: []Node{
+			Number{
+				Value:  2,
+				Raw:    "2",
+				Line:   1,
+				Column: 3,
+				Offset: 2,
+			},
+			nil,
+		},
+		Line:   1,
+		Column: 2,
+		Offset: 1,
+		Rbrack: Position{Offset: 6, Line: 1, Column: 7},
+	}
+	result, err := Parse(text)
+	if err != nil {
+		t.Fatalf("TestBrackets failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestBrackets failed. Expected: %s, Got: %s", expect, result)
+	}
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{"[1 2 3]", "[1, 2, 3]"},
+		{"[1 - 2]", "[(1 - 2)]"},
+		{"[2x y]", "[(2 x), y]"},
+		{"[a and b, c]", "[(a and b), c]"},
+		{"[[1 2] [3 4]]", "[[1, 2], [3, 4]]"},
+		{"[1 2; 3 4]", "[1, 2; 3, 4]"},
+		{"[f(x) (y)]", "[f(x), y]"},
+		{"v[1] + 2", "(v[1]) + 2"},
+		{"2v[1]", "2 (v[1])"},
+		{"f(x)[1]", "(f(x))[1]"},
+		{"[1, 2][1]", "([1, 2])[1]"},
+		{"m[1][2]", "(m[1])[2]"},
+	}
+	for _, test := range tests {
+		n, err := Parse(test.text)
+		if err != nil {
+			t.Errorf("TestBrackets failed for %q. Got: %s", test.text, err)
+			continue
+		}
+		m, _ := Parse(test.expect)
+		if !Equal(unparen(n), unparen(m), IgnorePositions()) {
+			t.Errorf("TestBrackets failed for %q. Expected: %s, Got: %s", test.text, test.expect, Print(n))
+		}
+	}
+	// A semicolon makes a matrix, even of a single row.
+	result, _ = Parse("[1 2;]")
+	if m, ok := result.(Matrix); !ok || len(m.Rows) != 1 {
+		t.Errorf("TestBrackets failed. Expected: Matrix of one row, Got: %s", result)
+	}
+	errs := []struct {
+		text string
+		kind ErrorKind
+	}{
+		{"[1, 2", MissingDelimiter},
+		{"v[1", MissingDelimiter},
+		{"[1, ]", UndefinedPrefix},
+		{"v[]", UndefinedPrefix},
+	}
+	for _, test := range errs {
+		_, err := Parse(test.text)
+		if e, ok := err.(*Error); !ok || e.Kind != test.kind {
+			t.Errorf("TestBrackets failed for %q. Expected: %s, Got: %v", test.text, test.kind, err)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	text := `name = "Ada"`
+	expect := Binary{
+		Op: "=",
+		X: Symbol{
+			Value:  "name",
+			Line:   1,
+			Column: 1,
+		},
+		Y: String{
+			Value:  "Ada",
+			Raw:    `"Ada"`,
+			Line:   1,
+			Column: 8,
+			Offset: 7,
+		},
+		Line:   1,
+		Column: 6,
+		Offset: 5,
+	}
+	result, err := Parse(text)
+	if err != nil {
+		t.Fatalf("TestStrings failed. Expected: %s, Got: %s", expect, err)
+	}
+	if !Equal(expect, result) {
+		t.Errorf("TestStrings failed. Expected: %s, Got: %s", expect, result)
+	}
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{`'a' + "b"`, `'a' + "b"`},
+		{`upper('it\'s')`, `upper('it\'s')`},
+		{`x = 'it' and y`, `(x = 'it') and y`},
+		{`["a" 'b']`, `["a", 'b']`},
+		{`s = "x" ? 1 : 2`, `(s = "x") ? 1 : 2`},
+	}
+	for _, test := range tests {
+		n, err := Parse(test.text)
+		if err != nil {
+			t.Errorf("TestStrings failed for %q. Got: %s", test.text, err)
+			continue
+		}
+		m, _ := Parse(test.expect)
+		if !Equal(unparen(n), unparen(m), IgnorePositions()) {
+			t.Errorf("TestStrings failed for %q. Expected: %s, Got: %s", test.text, test.expect, Print(n))
+		}
+	}
+	// Unterminated strings are reported at their opening quote.
+	_, err = Parse("1 +\n 'abc")
+	if e, ok := err.(*Error); !ok || e.Kind != LexicalError || e.Pos.Line != 2 || e.Pos.Column != 2 {
+		t.Errorf("TestStrings failed. Expected: lexical error at line:2 column:2, Got: %v", err)
+	}
+	_, err = Parse(`x = "\z"`)
+	if e, ok := err.(*Error); !ok || e.Kind != LexicalError || e.Pos.Column != 6 {
+		t.Errorf("TestStrings failed. Expected: lexical error at column 6, Got: %v", err)
+	}
+}
@@ -1,9 +1,10 @@
 package parser
 
 import (
-	"fmt"
-	"github/jared-richard-clarke/pratt/internal/lexer"
+	"github/jared-richard-clarke/pratt/lexer"
+	"io"
 	"strconv"
+	"strings"
 )
 
 // Top down operator precedence parsing, as imagined by Vaughan Pratt,
@@ -18,112 +19,268 @@ import (
 // 1. null denotation ( nud ): a lexeme without a left expression.
 // 2. left denotation ( led ): a lexeme with a left expression.
 
-// Parses numbers, symbols, and unary operators
-type nud func(lexer.Token) (Node, error)
+// Parser holds the state of a single parse: the token source, the
+// position within it, and the grammar that gives each token meaning.
+// Handlers registered with a Grammar receive the Parser so that they
+// can consume tokens and parse subexpressions. Tokens are scanned on
+// demand, one ahead of the parse, so no more than two are held at once.
+//
+// A Parser output by "New" holds only a grammar: each of its Parse
+// methods starts a parse of its own. Its token methods are meant for
+// handlers alone. Outside a parse, "Next" and "Peek" output EOF and
+// "ParseExpression" fails with an InputError.
+type Parser struct {
+	name  string         // source file name, if any
+	src   *lexer.Scanner // token source
+	ahead lexer.Token    // next token, scanned but not yet consumed
+	last  lexer.Token    // last token consumed
+	count int            // number of tokens consumed
+	g     *Grammar       // parser and binding lookup
+	all   bool           // if true, recovers from errors and records them
+	errs  ErrorList      // errors recorded while recovering
+}
 
-// Parses binary operators
-type led func(Node, lexer.Token) (Node, error)
+// Inputs a grammar, outputs a parser for that grammar. The grammar is
+// copied, so later changes to "g" do not affect the parser. A nil
+// grammar selects the default arithmetic grammar.
+func New(g *Grammar) *Parser {
+	if g == nil {
+		g = grammar
+	} else {
+		g = g.Clone()
+	}
+	return &Parser{
+		ahead: lexer.Token{Typeof: lexer.EOF, Line: 1, Column: 1},
+		g:     g,
+	}
+}
 
-type table struct {
-	nud  map[lexer.LexType]nud // lexeme -> nud
-	led  map[lexer.LexType]led // lexeme -> led
-	bind map[lexer.LexType]int // lexeme -> binding power
+// Consumes and returns the next token.
+func (p *Parser) Next() lexer.Token {
+	t := p.ahead
+	// From EOF onwards, returns EOF.
+	if t.Typeof != lexer.EOF {
+		p.ahead = p.src.Next()
+	}
+	p.last = t
+	p.count += 1
+	return t
 }
 
-type parser struct {
-	src    []lexer.Token // token source
-	index  int           // src[index]
-	end    int           // src[len(src) - 1]
-	*table               // parser and binding lookup
+// Returns the next token without consuming it.
+func (p *Parser) Peek() lexer.Token {
+	return p.ahead
 }
 
-func (p *parser) next() lexer.Token {
-	// From final index onwards, returns final token — usually EOF.
-	if p.index >= p.end {
-		return p.src[p.end]
+// Reports whether the next token is of type "expect".
+func (p *Parser) Match(expect lexer.LexType) bool {
+	return p.Peek().Typeof == expect
+}
+
+// When recovering, records "err" and outputs nil so that parsing
+// may continue. Otherwise outputs "err" unchanged.
+func (p *Parser) report(err *Error) error {
+	if !p.all {
+		return err
 	}
-	t := p.src[p.index]
-	p.index += 1
-	return t
+	p.errs = append(p.errs, err)
+	return nil
 }
 
-func (p *parser) peek() lexer.LexType {
-	return p.src[p.index].Typeof
+// When recovering, records "err", skips ahead to a token from which
+// parsing may resume, and outputs a Bad node in place of the expression
+// begun by "token". Otherwise outputs "err" unchanged.
+func (p *Parser) recover(token lexer.Token, err error) (Node, error) {
+	if !p.all {
+		return nil, err
+	}
+	e, ok := err.(*Error)
+	if !ok {
+		// Semantic code registered by the user may return any error.
+		e = p.errorf(SyntaxError, token, "%s", err)
+		e.Err = err
+	}
+	p.errs = append(p.errs, e)
+	if token.Typeof == lexer.OpenParen {
+		// Unbalanced: skip through the matching ')'.
+		p.sync(1)
+	} else {
+		p.sync(0)
+	}
+	return p.bad(token), nil
 }
 
-func (p *parser) match(expect lexer.LexType) bool {
-	return p.peek() == expect
+// Outputs a Bad node spanning "token" through the last token consumed.
+// Zero width if "token" itself was not consumed.
+func (p *Parser) bad(token lexer.Token) Bad {
+	pos, to := p.span(token)
+	if p.count > 0 {
+		start, last := p.span(p.last)
+		switch {
+		case start.Offset < pos.Offset:
+			// "token" was left for the enclosing expression.
+			to = pos
+		case last.Offset > to.Offset:
+			to = last
+		}
+	}
+	return Bad{
+		Line:   pos.Line,
+		Column: pos.Column,
+		Offset: pos.Offset,
+		To:     to,
+	}
+}
+
+// Skips tokens until a comma, closing parenthesis, infix operator,
+// or EOF. Skips parenthesized tokens whole. "depth" counts the
+// open parentheses already consumed.
+func (p *Parser) sync(depth int) {
+	for !p.Match(lexer.EOF) {
+		t := p.Peek().Typeof
+		if depth == 0 {
+			if t == lexer.Comma || t == lexer.CloseParen {
+				return
+			}
+			if t != lexer.OpenParen && p.g.bind[t] > 0 {
+				return
+			}
+		}
+		switch t {
+		case lexer.OpenParen:
+			depth += 1
+		case lexer.CloseParen:
+			depth -= 1
+		}
+		p.Next()
+	}
 }
 
-// The engine of Pratt's technique, "parseExpression" drives the parser,
+// The engine of Pratt's technique, "ParseExpression" drives the parser,
 // calling the semantic code of each lexeme in turn from left to right.
 // For every level of precedence — dictated by position and binding power —
-// there is a call to "parseExpression" either through the "nud" or "led"
-// of the associated lexeme. The resolution of "parseExpression" is to
+// there is a call to "ParseExpression" either through the "nud" or "led"
+// of the associated lexeme. The resolution of "ParseExpression" is to
 // return either the branch of an abstract syntax tree or an error.
-func (p *parser) parseExpression(rbp int) (Node, error) {
-	token := p.next()
-	nud, ok := p.nud[token.Typeof]
+func (p *Parser) ParseExpression(rbp int) (Node, error) {
+	if p.src == nil {
+		token := p.Peek()
+		return nil, p.errorf(InputError, token, "no input: ParseExpression called outside a parse")
+	}
+	token := p.Peek()
+	nud, ok := p.g.nud[token.Typeof]
 	if !ok {
-		msg := "undefined prefix operation %q line:%d column:%d"
-		return nil, fmt.Errorf(msg, token.Value, token.Line, token.Column)
+		err := p.errorf(UndefinedPrefix, token, "undefined prefix operation %q", token.Value)
+		nud = func(*Parser, lexer.Token) (Node, error) { return nil, err }
 	}
-	left, err := nud(token)
+	// When recovering, leaves an unexpected delimiter for the enclosing
+	// grouping or call.
+	if ok || !p.all || (token.Typeof != lexer.CloseParen && token.Typeof != lexer.Comma) {
+		p.Next()
+	}
+	left, err := nud(p, token)
 	if err != nil {
-		return nil, err
+		if left, err = p.recover(token, err); err != nil {
+			return nil, err
+		}
 	}
-	for rbp < p.bind[p.peek()] {
-		token := p.next()
-		led, ok := p.led[token.Typeof]
+	for rbp < p.g.bind[p.Peek().Typeof] {
+		token := p.Next()
+		led, ok := p.g.led[token.Typeof]
 		if !ok {
-			msg := "undefined infix operation %q line:%d column:%d"
-			return nil, fmt.Errorf(msg, token.Value, token.Line, token.Column)
+			err := p.errorf(UndefinedInfix, token, "undefined infix operation %q", token.Value)
+			led = func(*Parser, Node, lexer.Token) (Node, error) { return nil, err }
 		}
-		left, err = led(left, token)
+		left, err = led(p, left, token)
 		if err != nil {
-			return nil, err
+			if left, err = p.recover(token, err); err != nil {
+				return nil, err
+			}
 		}
 	}
 	return left, nil
 }
 
 // Parses either empty or incomplete expressions.
-func (p *parser) parseEOF(token lexer.Token) (Node, error) {
-	if len(p.src) == 1 {
+func (p *Parser) parseEOF(token lexer.Token) (Node, error) {
+	// An empty expression consists of EOF alone.
+	if p.count == 1 {
 		return Empty{}, nil
 	}
-	msg := "incomplete parseExpression, unexpected <EOF> line:%d column:%d"
-	err := fmt.Errorf(msg, token.Line, token.Column)
-	return nil, err
+	return nil, p.errorf(UnexpectedEOF, token, "incomplete expression, unexpected <EOF>")
+}
+
+// Parses unexpected characters, already reported by the lexer.
+// In place of an operand, outputs a Bad node.
+func (p *Parser) parseIllegal(token lexer.Token) (Node, error) {
+	return p.bad(token), nil
+}
+
+// Parses unexpected characters, already reported by the lexer.
+// In place of an operator, skips the character.
+func (p *Parser) skipIllegal(left Node, token lexer.Token) (Node, error) {
+	return left, nil
 }
 
 // Parses numbers as 64-bit floating point.
-func (p *parser) parseNumber(token lexer.Token) (Node, error) {
+func (p *Parser) parseNumber(token lexer.Token) (Node, error) {
 	num, err := strconv.ParseFloat(token.Value, 64)
 	if err != nil {
-		msg := "invalid number: %s line:%d column:%d"
-		return nil, fmt.Errorf(msg, token.Value, token.Line, token.Column)
+		return nil, p.errorf(InvalidNumber, token, "invalid number: %s", token.Value)
 	}
 	return Number{
 		Value:  num,
+		Raw:    token.Value,
 		Line:   token.Line,
 		Column: token.Column,
+		Offset: p.pos(token).Offset,
 	}, nil
 }
 
-// Parses symbols — otherwise known as identifiers.
-// Always returns Node. Has error type to satisfy "nud".
-func (p *parser) parseSymbol(token lexer.Token) (Node, error) {
-	return Symbol{
+// Parses string literals, interpreting their escapes.
+func (p *Parser) parseString(token lexer.Token) (Node, error) {
+	s, err := lexer.Unquote(token.Value)
+	if err != nil {
+		return nil, p.errorf(InvalidString, token, "invalid string: %s", token.Value)
+	}
+	return String{
+		Value:  s,
+		Raw:    token.Value,
+		Line:   token.Line,
+		Column: token.Column,
+		Offset: p.pos(token).Offset,
+	}, nil
+}
+
+// Parses symbols — otherwise known as identifiers. A symbol followed
+// by an arrow is the lone parameter of a lambda, as in "x -> 2x".
+func (p *Parser) parseSymbol(token lexer.Token) (Node, error) {
+	s := Symbol{
 		Value:  token.Value,
 		Line:   token.Line,
 		Column: token.Column,
+		Offset: p.pos(token).Offset,
+	}
+	if p.Match(lexer.Arrow) {
+		return p.parseLambda(Lambda{Params: []Symbol{s}})
+	}
+	return s, nil
+}
+
+// Parses the boolean literals "true" and "false".
+// Always returns Node. Has error type to satisfy "nud".
+func (p *Parser) parseBoolean(token lexer.Token) (Node, error) {
+	return Boolean{
+		Value:  token.Value == "true",
+		Line:   token.Line,
+		Column: token.Column,
+		Offset: p.pos(token).Offset,
 	}, nil
 }
 
 // Parses unary expressions.
-func (p *parser) parseUnary(token lexer.Token) (Node, error) {
-	node, err := p.parseExpression(p.bind[token.Typeof])
+func (p *Parser) parseUnary(token lexer.Token) (Node, error) {
+	node, err := p.ParseExpression(p.g.prefix[token.Typeof])
 	if err != nil {
 		return nil, err
 	}
@@ -132,20 +289,37 @@ func (p *parser) parseUnary(token lexer.Token) (Node, error) {
 		X:      node,
 		Line:   token.Line,
 		Column: token.Column,
+		Offset: p.pos(token).Offset,
+	}, nil
+}
+
+// Parses postfix expressions, which take no right operand.
+// Always returns Node. Has error type to satisfy "led".
+func (p *Parser) parsePostfix(left Node, token lexer.Token) (Node, error) {
+	return Postfix{
+		Op:     token.Value,
+		X:      left,
+		Line:   token.Line,
+		Column: token.Column,
+		Offset: p.pos(token).Offset,
+		Size:   token.Size,
 	}, nil
 }
 
 // Parses binary expressions that associate left.
-func (p *parser) parseBinaryLeft(left Node, token lexer.Token) (Node, error) {
-	right, err := p.parseExpression(p.bind[token.Typeof])
+func (p *Parser) parseBinaryLeft(left Node, token lexer.Token) (Node, error) {
+	right, err := p.ParseExpression(p.g.bind[token.Typeof])
 	if err != nil {
 		return nil, err
 	}
 	if token.Typeof == lexer.ImpMul {
 		return ImpliedBinary{
-			Op: token.Value,
-			X:  left,
-			Y:  right,
+			Op:     token.Value,
+			X:      left,
+			Y:      right,
+			Line:   token.Line,
+			Column: token.Column,
+			Offset: p.pos(token).Offset,
 		}, nil
 	}
 	return Binary{
@@ -154,12 +328,13 @@ func (p *parser) parseBinaryLeft(left Node, token lexer.Token) (Node, error) {
 		Y:      right,
 		Line:   token.Line,
 		Column: token.Column,
+		Offset: p.pos(token).Offset,
 	}, nil
 }
 
 // Parses binary expressions that associate right.
-func (p *parser) parseBinaryRight(left Node, token lexer.Token) (Node, error) {
-	right, err := p.parseExpression(p.bind[token.Typeof] - 1)
+func (p *Parser) parseBinaryRight(left Node, token lexer.Token) (Node, error) {
+	right, err := p.ParseExpression(p.g.bind[token.Typeof] - 1)
 	if err != nil {
 		return nil, err
 	}
@@ -169,133 +344,607 @@ func (p *parser) parseBinaryRight(left Node, token lexer.Token) (Node, error) {
 		Y:      right,
 		Line:   token.Line,
 		Column: token.Column,
+		Offset: p.pos(token).Offset,
 	}, nil
 }
 
-// Parses parenthetical expressions.
-func (p *parser) parseGrouping(token lexer.Token) (Node, error) {
-	position := fmt.Sprintf("line:%d column:%d", token.Line, token.Column)
-	node, err := p.parseExpression(0)
+// Parses non-associative binary expressions, such as comparisons.
+// Outputs Binary nodes or, for chains such as "0 ≤ x < 10", Comparison
+// nodes, unless the grammar rejects chains.
+func (p *Parser) parseNonAssoc(left Node, token lexer.Token) (Node, error) {
+	bp := p.g.bind[token.Typeof]
+	right, err := p.ParseExpression(bp)
 	if err != nil {
 		return nil, err
 	}
-	if !p.match(lexer.CloseParen) {
-		msg := "for '(' %s, missing matching ')'"
-		return nil, fmt.Errorf(msg, position)
+	if !p.chains(bp) {
+		return Binary{
+			Op:     token.Value,
+			X:      left,
+			Y:      right,
+			Line:   token.Line,
+			Column: token.Column,
+			Offset: p.pos(token).Offset,
+		}, nil
 	}
-	p.next()
-	return node, nil
+	chain := Comparison{
+		Ops:      []string{token.Value},
+		Operands: []Node{left, right},
+		Line:     token.Line,
+		Column:   token.Column,
+		Offset:   p.pos(token).Offset,
+	}
+	for p.chains(bp) {
+		next := p.Next()
+		if p.g.chain == RejectChains {
+			err := p.errorf(NonAssociative, next, "%q cannot follow %q without parentheses", next.Value, chain.Ops[len(chain.Ops)-1])
+			if err := p.report(err); err != nil {
+				return nil, err
+			}
+		}
+		right, err := p.ParseExpression(bp)
+		if err != nil {
+			return nil, err
+		}
+		chain.Ops = append(chain.Ops, next.Value)
+		chain.Operands = append(chain.Operands, right)
+	}
+	return chain, nil
 }
 
-// Parses function calls.
-func (p *parser) parseCall(left Node, token lexer.Token) (Node, error) {
-	// For now, the only valid function callees are symbols.
-	s, ok := left.(Symbol)
-	if !ok {
-		msg := "%s is not a callable function"
-		return nil, fmt.Errorf(msg, left)
+// Reports whether the next token continues a chain of non-associative
+// operators of binding power "bp".
+func (p *Parser) chains(bp int) bool {
+	t := p.Peek().Typeof
+	return p.g.assoc[t] == NonAssoc && p.g.bind[t] == bp
+}
+
+// Parses parenthetical expressions, and the parenthesized parameters
+// of lambdas: "() -> 1", "(x) -> x", and "(x, y) -> x + y".
+func (p *Parser) parseGrouping(token lexer.Token) (Node, error) {
+	if p.Match(lexer.CloseParen) {
+		return p.parseParams(token, nil)
+	}
+	node, err := p.ParseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.Match(lexer.Comma) {
+		return p.parseParams(token, []Node{node})
+	}
+	paren := Paren{
+		X:      node,
+		Lparen: p.pos(token),
+	}
+	if !p.Match(lexer.CloseParen) {
+		return paren, p.report(p.errorf(MissingParen, token, "for '(', missing matching ')'"))
+	}
+	paren.Rparen = p.pos(p.Next())
+	if s, ok := node.(Symbol); ok && p.Match(lexer.Arrow) {
+		return p.parseLambda(Lambda{
+			Params: []Symbol{s},
+			Lparen: paren.Lparen,
+			Rparen: paren.Rparen,
+		})
+	}
+	return paren, nil
+}
+
+// Parses the remaining parameters of a lambda, following "params",
+// through its closing parenthesis, then the lambda itself.
+func (p *Parser) parseParams(token lexer.Token, params []Node) (Node, error) {
+	// Reports a first parameter that is not a name before any that follow.
+	if _, err := p.symbols(params); err != nil {
+		return nil, err
+	}
+	for p.Match(lexer.Comma) {
+		p.Next()
+		if !p.Match(lexer.Symbol) {
+			return nil, p.errorf(InvalidBinding, p.Peek(), "expected a parameter name, got %q", p.Peek().Value)
+		}
+		t := p.Next()
+		params = append(params, Symbol{
+			Value:  t.Value,
+			Line:   t.Line,
+			Column: t.Column,
+			Offset: p.pos(t).Offset,
+		})
+	}
+	if !p.Match(lexer.CloseParen) {
+		return nil, p.errorf(MissingParen, token, "for '(', missing matching ')'")
+	}
+	rparen := p.Next()
+	if !p.Match(lexer.Arrow) {
+		return nil, p.errorf(MissingDelimiter, rparen, "for parameters, missing '->'")
+	}
+	symbols, err := p.symbols(params)
+	if err != nil {
+		return nil, err
+	}
+	return p.parseLambda(Lambda{
+		Params: symbols,
+		Lparen: p.pos(token),
+		Rparen: p.pos(rparen),
+	})
+}
+
+// Parses the arrow and body of lambda "l", whose parameters are parsed.
+// The body extends as far right as possible.
+func (p *Parser) parseLambda(l Lambda) (Node, error) {
+	arrow := p.Next()
+	body, err := p.ParseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	l.Body = body
+	l.Line = arrow.Line
+	l.Column = arrow.Column
+	l.Offset = p.pos(arrow).Offset
+	return l, nil
+}
+
+// Parses function definitions, "f(x, y) := body", whose left side
+// parses as a call. The body extends as far right as possible.
+func (p *Parser) parseFuncDef(left Node, token lexer.Token) (Node, error) {
+	call, ok := left.(Call)
+	name, named := call.Callee.(Symbol)
+	if !ok || !named || call.Rparen.Line == 0 {
+		return nil, p.errorf(InvalidBinding, token, "for ':=', expected a function name and parameters, got %s", Print(left))
+	}
+	params, err := p.symbols(call.Args)
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.ParseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	return FuncDef{
+		Name:   name,
+		Params: params,
+		Body:   body,
+		Line:   token.Line,
+		Column: token.Column,
+		Offset: p.pos(token).Offset,
+		Lparen: at(call.Offset, call.Line, call.Column),
+		Rparen: call.Rparen,
+	}, nil
+}
+
+// Outputs "nodes" as parameters, each of which must be a distinct symbol.
+func (p *Parser) symbols(nodes []Node) ([]Symbol, error) {
+	params := make([]Symbol, len(nodes))
+	for i, n := range nodes {
+		s, ok := n.(Symbol)
+		if !ok {
+			return nil, p.errorAt(InvalidBinding, n, "expected a parameter name, got %s", Print(n))
+		}
+		for _, prev := range params[:i] {
+			if prev.Value == s.Value {
+				return nil, p.errorAt(InvalidBinding, n, "duplicate parameter %q", s.Value)
+			}
+		}
+		params[i] = s
+	}
+	return params, nil
+}
+
+// Parses conditional expressions, "c ? x : y". Associates right, so
+// that "a ? b : c ? d : e" reads "a ? b : (c ? d : e)".
+func (p *Parser) parseTernary(left Node, token lexer.Token) (Node, error) {
+	then, err := p.ParseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Match(lexer.Colon) {
+		return nil, p.errorf(MissingDelimiter, token, "for '?', missing ':'")
+	}
+	colon := p.Next()
+	els, err := p.ParseExpression(p.g.bind[token.Typeof] - 1)
+	if err != nil {
+		return nil, err
 	}
-	if p.match(lexer.CloseParen) {
-		p.next()
+	return Conditional{
+		Cond:    left,
+		Then:    then,
+		Else:    els,
+		Line:    token.Line,
+		Column:  token.Column,
+		Offset:  p.pos(token).Offset,
+		ElsePos: p.pos(colon),
+	}, nil
+}
+
+// Parses conditional expressions, "if c then x else y". The "else"
+// branch extends as far right as possible.
+func (p *Parser) parseIf(token lexer.Token) (Node, error) {
+	cond, err := p.ParseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Match(lexer.Then) {
+		return nil, p.errorf(MissingDelimiter, token, "for 'if', missing 'then'")
+	}
+	then := p.Next()
+	x, err := p.ParseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.Match(lexer.Else) {
+		return nil, p.errorf(MissingDelimiter, token, "for 'if', missing 'else'")
+	}
+	els := p.Next()
+	y, err := p.ParseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	return Conditional{
+		Cond:    cond,
+		Then:    x,
+		Else:    y,
+		Keyword: true,
+		Line:    token.Line,
+		Column:  token.Column,
+		Offset:  p.pos(token).Offset,
+		ThenPos: p.pos(then),
+		ElsePos: p.pos(els),
+	}, nil
+}
+
+// Parses function calls. Any expression may be called, though only
+// symbols, their derivatives, such as "f'", parenthesized lambdas, and
+// calls, as in "k(1)(2)", are followed directly by a parenthesis.
+func (p *Parser) parseCall(left Node, token lexer.Token) (Node, error) {
+	if p.Match(lexer.CloseParen) {
 		return Call{
 			Callee: left,
 			Args:   make([]Node, 0), // Make an empty slice, not a nil slice. Makes comparisons simpler.
 			Line:   token.Line,
 			Column: token.Column,
+			Offset: p.pos(token).Offset,
+			Rparen: p.pos(p.Next()),
 		}, nil
 	}
 	var args []Node
 	for {
-		node, err := p.parseExpression(0)
+		node, err := p.ParseExpression(0)
 		if err != nil {
 			return nil, err
 		}
 		args = append(args, node)
-		if !p.match(lexer.Comma) {
+		if !p.Match(lexer.Comma) {
 			break
 		}
-		p.next()
-	}
-	if !p.match(lexer.CloseParen) {
-		msg := "for function call %q, missing closing ')'"
-		return nil, fmt.Errorf(msg, s.Value)
+		p.Next()
 	}
-	p.next()
-	return Call{
+	call := Call{
 		Callee: left,
 		Args:   args,
 		Line:   token.Line,
 		Column: token.Column,
-	}, nil
+		Offset: p.pos(token).Offset,
+	}
+	if !p.Match(lexer.CloseParen) {
+		return call, p.report(p.errorf(MissingParen, token, "for function call %q, missing closing ')'", Print(left)))
+	}
+	call.Rparen = p.pos(p.Next())
+	return call, nil
 }
 
-// Carries parser's internal state. Should persist throughout package lifetime.
-var pratt parser
-
-func init() {
-	// Build lookup table at package initialization.
-	pratt = parser{
-		table: &table{
-			nud:  make(map[lexer.LexType]nud),
-			led:  make(map[lexer.LexType]led),
-			bind: make(map[lexer.LexType]int),
-		},
+// Parses lists, "[1, 2, 3]", and matrices, "[1 2; 3 4]". Any semicolon
+// makes a matrix, so that "[1 2;]" is a matrix of one row. Within the
+// brackets, the lexer scans whitespace between elements as a comma.
+func (p *Parser) parseBrackets(token lexer.Token) (Node, error) {
+	lbrack := p.pos(token)
+	if p.Match(lexer.CloseBracket) {
+		return List{Elems: make([]Node, 0), Lbrack: lbrack, Rbrack: p.pos(p.Next())}, nil
+	}
+	var rows [][]Node
+	var row []Node
+	matrix := false
+	for {
+		node, err := p.ParseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		row = append(row, node)
+		if p.Match(lexer.Comma) {
+			p.Next()
+			continue
+		}
+		if !p.Match(lexer.Semicolon) {
+			break
+		}
+		p.Next()
+		matrix = true
+		rows = append(rows, row)
+		row = nil
+		if p.Match(lexer.CloseBracket) {
+			break
+		}
 	}
-	// Helper functions build lookup tables.
-	set := func(t lexer.LexType, n nud) {
-		pratt.nud[t] = n
-		pratt.bind[t] = 0
+	var rbrack Position
+	var err error
+	if p.Match(lexer.CloseBracket) {
+		rbrack = p.pos(p.Next())
+	} else {
+		err = p.report(p.errorf(MissingDelimiter, token, "for '[', missing matching ']'"))
 	}
-	prefix := func(n nud, ts ...lexer.LexType) {
-		for _, t := range ts {
-			pratt.nud[t] = n
+	if matrix {
+		if row != nil {
+			rows = append(rows, row)
 		}
+		return Matrix{Rows: rows, Lbrack: lbrack, Rbrack: rbrack}, err
+	}
+	return List{Elems: row, Lbrack: lbrack, Rbrack: rbrack}, err
+}
+
+// Parses indices, "v[2]" or "m[1, :]". A lone colon parses as a nil
+// index, which selects every element.
+func (p *Parser) parseIndex(left Node, token lexer.Token) (Node, error) {
+	index := Index{
+		X:      left,
+		Line:   token.Line,
+		Column: token.Column,
+		Offset: p.pos(token).Offset,
 	}
-	affix := func(bp int, l led, ts ...lexer.LexType) {
-		for _, t := range ts {
-			pratt.led[t] = l
-			pratt.bind[t] = bp
+	for {
+		var node Node
+		if p.Match(lexer.Colon) {
+			p.Next()
+		} else {
+			var err error
+			if node, err = p.ParseExpression(0); err != nil {
+				return nil, err
+			}
+		}
+		index.Indices = append(index.Indices, node)
+		if !p.Match(lexer.Comma) {
+			break
 		}
+		p.Next()
+	}
+	if !p.Match(lexer.CloseBracket) {
+		return index, p.report(p.errorf(MissingDelimiter, token, "for index of %q, missing closing ']'", Print(left)))
 	}
+	index.Rbrack = p.pos(p.Next())
+	return index, nil
+}
 
-	// Initialize lookup table.
-	set(lexer.EOF, pratt.parseEOF)
-	set(lexer.Number, pratt.parseNumber)
-	set(lexer.Symbol, pratt.parseSymbol)
-	set(lexer.OpenParen, pratt.parseGrouping)
-	prefix(pratt.parseUnary, lexer.Add, lexer.Sub)
-	affix(10, pratt.parseBinaryLeft, lexer.Equal, lexer.NotEqual)
-	affix(20, pratt.parseBinaryLeft, lexer.Add, lexer.Sub)
-	affix(30, pratt.parseBinaryLeft, lexer.Mul, lexer.Div)
-	affix(40, pratt.parseBinaryLeft, lexer.ImpMul)
-	affix(50, pratt.parseBinaryRight, lexer.Pow)
-	affix(60, pratt.parseCall, lexer.OpenParen)
+// Reports whether "n" may be called without parentheses around it: a
+// symbol, a symbol followed by primes, a string or boolean literal, a
+// list or matrix, an index, or a call.
+func callable(n Node) bool {
+	switch n := n.(type) {
+	case Symbol, String, Boolean, List, Matrix, Index, Call:
+		return true
+	case Postfix:
+		return n.Op == "'" && callable(n.X)
+	}
+	return false
 }
 
-// Parser API: inputs string, outputs either AST or Error
-func Parse(s string) (Node, error) {
-	// Transform string into tokens
-	ts, err := lexer.Scan(s)
+// Parses statements separated by semicolons. Skips empty statements.
+func (p *Parser) parseProgram() (Node, error) {
+	prog := Program{Stmts: make([]Node, 0)}
+	for {
+		for p.Match(lexer.Semicolon) {
+			p.Next()
+		}
+		if p.Match(lexer.EOF) {
+			return prog, nil
+		}
+		stmt, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		prog.Stmts = append(prog.Stmts, stmt)
+		if !p.Match(lexer.Semicolon) && !p.Match(lexer.EOF) {
+			return nil, p.errorf(MissingDelimiter, p.Peek(), "expected ';' or line break before %q", p.Peek().Value)
+		}
+	}
+}
+
+// Parses a statement: an assignment or an expression.
+func (p *Parser) parseStatement() (Node, error) {
+	if p.Match(lexer.Let) {
+		return p.parseLet(p.Next())
+	}
+	node, err := p.ParseExpression(0)
 	if err != nil {
 		return nil, err
 	}
-	// Set parser state.
-	pratt = parser{
-		src:   ts,
-		index: 0,
-		end:   len(ts) - 1,
-		table: pratt.table, // Reuse lookup table from package initialization.
+	// "name = value" assigns rather than tests equality.
+	if b, ok := node.(Binary); ok && b.Op == "=" {
+		if s, ok := b.X.(Symbol); ok {
+			return Assign{
+				Name:   s,
+				Value:  b.Y,
+				Line:   b.Line,
+				Column: b.Column,
+				Offset: b.Offset,
+			}, nil
+		}
 	}
-	// Weave tokens into abstract syntax tree.
-	node, err := pratt.parseExpression(0)
+	return node, nil
+}
+
+// Parses "let name = value".
+func (p *Parser) parseLet(token lexer.Token) (Node, error) {
+	if !p.Match(lexer.Symbol) {
+		return nil, p.errorf(InvalidBinding, p.Peek(), "for 'let', expected a name, got %q", p.Peek().Value)
+	}
+	name, _ := p.parseSymbol(p.Next())
+	if !p.Match(lexer.Equal) {
+		return nil, p.errorf(MissingDelimiter, token, "for 'let', missing '='")
+	}
+	eq := p.Next()
+	value, err := p.ParseExpression(0)
 	if err != nil {
 		return nil, err
 	}
-	// If unused tokens following expression, return error.
-	if pratt.index < pratt.end {
-		token := pratt.src[pratt.index]
-		msg := "starting line:%d, column:%d, unused tokens following expression"
-		return nil, fmt.Errorf(msg, token.Line, token.Column)
+	return Assign{
+		Name:   name.(Symbol),
+		Value:  value,
+		Line:   eq.Line,
+		Column: eq.Column,
+		Offset: p.pos(eq).Offset,
+		Let:    p.pos(token),
+	}, nil
+}
+
+// Parser API: inputs string, outputs either AST or Error.
+// Parses with the default arithmetic grammar. Safe for concurrent use.
+func Parse(s string) (Node, error) {
+	return New(nil).Parse(s)
+}
+
+// Inputs string, outputs either AST or Error. Each call builds its
+// own parser state, so a single Parser is safe for concurrent use.
+func (p *Parser) Parse(s string) (Node, error) {
+	return p.parse("", strings.NewReader(s), false)
+}
+
+// Like "Parse" but reads the source text of file "f". Errors are
+// positioned within the file and carry its name.
+func (p *Parser) ParseFile(f *File) (Node, error) {
+	return p.parse(f.Name(), strings.NewReader(f.Source()), false)
+}
+
+// Like "Parse" but reads source text from "r", scanning tokens only as
+// the parser needs them. Errors carry the file name "name", which may
+// be empty.
+func (p *Parser) ParseReader(name string, r io.Reader) (Node, error) {
+	return p.parse(name, r, false)
+}
+
+// Parser API: inputs string, outputs either Program or Error. Parses
+// with the default arithmetic grammar. Safe for concurrent use.
+func ParseProgram(s string) (Node, error) {
+	return New(nil).ParseProgram(s)
+}
+
+// Like "Parse" but reads a sequence of statements separated by
+// semicolons or line breaks, and outputs a Program. A line break
+// separates statements only where a statement could end and the next
+// line could not continue it: "1 +\n2" and "1\n+ 2" are each one
+// statement. A statement "name = value" or "let name = value"
+// outputs an Assign rather than testing equality.
+//
+//	r = 3; area = pi r^2
+//	area
+func (p *Parser) ParseProgram(s string) (Node, error) {
+	return p.parse("", strings.NewReader(s), true)
+}
+
+// Reads an expression, or a program if "program" is true.
+func (p *Parser) parse(name string, r io.Reader, program bool) (Node, error) {
+	sc := lexer.NewScanner(r)
+	sc.Semicolons = program
+	q := p.start(name, sc)
+	// Weave tokens into abstract syntax tree.
+	var node Node
+	var err error
+	if program {
+		node, err = q.parseProgram()
+	} else {
+		node, err = q.ParseExpression(0)
+	}
+	if err == nil {
+		// If unused tokens following expression, return error.
+		if e := q.unused(); e != nil {
+			err = e
+		}
+	}
+	// The lexer stops at the first unexpected character, cutting the
+	// tokens short. Its error takes precedence over any that follow.
+	// Input beyond the first error is left unread.
+	if e := sc.Err(); e != nil {
+		return nil, q.scanError(e)
+	}
+	if err != nil {
+		return nil, err
 	}
 	return node, nil
 }
+
+// Parser API: inputs string, outputs an AST together with every error
+// found along the way. Parses with the default arithmetic grammar.
+func ParseAll(s string) (Node, ErrorList) {
+	return New(nil).ParseAll(s)
+}
+
+// Like "Parse" but recovers from errors rather than stopping at the
+// first one. Each failed expression is replaced by a Bad node, and
+// parsing resumes at the next comma, closing parenthesis, or operator.
+// Outputs a partial AST and an ErrorList, sorted by position, which is
+// nil only if the input is free of errors.
+func (p *Parser) ParseAll(s string) (Node, ErrorList) {
+	return p.parseAll("", strings.NewReader(s))
+}
+
+// Like "ParseAll" but reads the source text of file "f". Errors are
+// positioned within the file and carry its name.
+func (p *Parser) ParseAllFile(f *File) (Node, ErrorList) {
+	return p.parseAll(f.Name(), strings.NewReader(f.Source()))
+}
+
+// Like "ParseAll" but reads source text from "r", scanning tokens only
+// as the parser needs them. Errors carry the file name "name", which
+// may be empty.
+func (p *Parser) ParseAllReader(name string, r io.Reader) (Node, ErrorList) {
+	return p.parseAll(name, r)
+}
+
+func (p *Parser) parseAll(name string, r io.Reader) (Node, ErrorList) {
+	sc := lexer.NewScanner(r)
+	sc.Recover = true
+	q := p.start(name, sc)
+	q.all = true
+	// While recovering, "ParseExpression" records its errors
+	// instead of returning them.
+	node, _ := q.ParseExpression(0)
+	if err := q.unused(); err != nil {
+		q.errs = append(q.errs, err)
+	}
+	// Scans the rest of the input for lexical errors.
+	q.drain()
+	for _, e := range sc.Errors() {
+		q.errs = append(q.errs, q.scanError(e))
+	}
+	if err := sc.Err(); err != nil {
+		q.errs = append(q.errs, q.scanError(err))
+	}
+	q.errs.Sort()
+	if len(q.errs) == 0 {
+		return node, nil
+	}
+	return node, q.errs
+}
+
+// Sets parser state for a single parse, reading the first token.
+func (p *Parser) start(name string, sc *lexer.Scanner) *Parser {
+	return &Parser{
+		name:  name,
+		src:   sc,
+		ahead: sc.Next(),
+		g:     p.g,
+	}
+}
+
+// If tokens remain after the top-level expression, outputs an error
+// spanning the first of them. Reads no further, so that a stream
+// need not be read to its end.
+func (p *Parser) unused() *Error {
+	if p.Match(lexer.EOF) {
+		return nil
+	}
+	return p.errorf(UnusedTokens, p.Peek(), "unused tokens following expression")
+}
+
+// Consumes any tokens remaining before EOF.
+func (p *Parser) drain() {
+	for !p.Match(lexer.EOF) {
+		p.Next()
+	}
+}
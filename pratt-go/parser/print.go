@@ -1,8 +1,14 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"github/jared-richard-clarke/pratt/lexer"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 const newline = "\n"
@@ -40,6 +46,16 @@ func (p *printer) format(n *Node) {
 	close := "}" + newline
 
 	switch n := (*n).(type) {
+	case Bad:
+		label := "Bad{" + newline
+		line := li(n.Line)
+		column := co(n.Column)
+
+		p.write(label)
+		p.indent()
+		p.writepad(line, column)
+		p.outdent()
+		p.writepad(close)
 	case Number:
 		label := "Number{" + newline
 		value := fmt.Sprintf("Value:  %g%s", n.Value, newline)
@@ -57,6 +73,28 @@ func (p *printer) format(n *Node) {
 		line := li(n.Line)
 		column := co(n.Column)
 
+		p.write(label)
+		p.indent()
+		p.writepad(value, line, column)
+		p.outdent()
+		p.writepad(close)
+	case String:
+		label := "String{" + newline
+		value := fmt.Sprintf("Value:  %q%s", n.Value, newline)
+		line := li(n.Line)
+		column := co(n.Column)
+
+		p.write(label)
+		p.indent()
+		p.writepad(value, line, column)
+		p.outdent()
+		p.writepad(close)
+	case Boolean:
+		label := "Boolean{" + newline
+		value := fmt.Sprintf("Value:  %t%s", n.Value, newline)
+		line := li(n.Line)
+		column := co(n.Column)
+
 		p.write(label)
 		p.indent()
 		p.writepad(value, line, column)
@@ -68,6 +106,20 @@ func (p *printer) format(n *Node) {
 		line := li(n.Line)
 		column := co(n.Column)
 
+		p.write(label)
+		p.indent()
+		p.writepad(op)
+		p.writepad("X: ")
+		p.format(&n.X)
+		p.writepad(line, column)
+		p.outdent()
+		p.writepad(close)
+	case Postfix:
+		label := "Postfix{" + newline
+		op := fmt.Sprintf("Op: %q%s", n.Op, newline)
+		line := li(n.Line)
+		column := co(n.Column)
+
 		p.write(label)
 		p.indent()
 		p.writepad(op)
@@ -95,6 +147,8 @@ func (p *printer) format(n *Node) {
 	case ImpliedBinary:
 		label := "ImpliedBinary{" + newline
 		op := fmt.Sprintf("Op: %q%s", n.Op, newline)
+		line := li(n.Line)
+		column := co(n.Column)
 
 		p.write(label)
 		p.indent()
@@ -103,6 +157,168 @@ func (p *printer) format(n *Node) {
 		p.format(&n.X)
 		p.writepad("Y: ")
 		p.format(&n.Y)
+		p.writepad(line, column)
+		p.outdent()
+		p.writepad(close)
+	case Paren:
+		label := "Paren{" + newline
+		lparen := fmt.Sprintf("Lparen: %s%s", n.Lparen, newline)
+		rparen := fmt.Sprintf("Rparen: %s%s", n.Rparen, newline)
+
+		p.write(label)
+		p.indent()
+		p.writepad("X: ")
+		p.format(&n.X)
+		p.writepad(lparen, rparen)
+		p.outdent()
+		p.writepad(close)
+	case Comparison:
+		label := "Comparison{" + newline
+		ops := fmt.Sprintf("Ops: %q%s", n.Ops, newline)
+		line := li(n.Line)
+		column := co(n.Column)
+
+		p.write(label)
+		p.indent()
+		p.writepad(ops)
+		p.writepad("Operands: [" + newline)
+		p.indent()
+		for _, x := range n.Operands {
+			p.writepad("") // pad each operand
+			p.format(&x)
+		}
+		p.outdent()
+		p.writepad("]" + newline)
+		p.writepad(line, column)
+		p.outdent()
+		p.writepad(close)
+	case Conditional:
+		label := "Conditional{" + newline
+		keyword := fmt.Sprintf("Keyword: %t%s", n.Keyword, newline)
+		line := li(n.Line)
+		column := co(n.Column)
+		then := fmt.Sprintf("ThenPos: %s%s", n.ThenPos, newline)
+		els := fmt.Sprintf("ElsePos: %s%s", n.ElsePos, newline)
+
+		p.write(label)
+		p.indent()
+		p.writepad("Cond: ")
+		p.format(&n.Cond)
+		p.writepad("Then: ")
+		p.format(&n.Then)
+		p.writepad("Else: ")
+		p.format(&n.Else)
+		p.writepad(keyword, line, column, then, els)
+		p.outdent()
+		p.writepad(close)
+	case Assign:
+		label := "Assign{" + newline
+		line := li(n.Line)
+		column := co(n.Column)
+		let := fmt.Sprintf("Let: %s%s", n.Let, newline)
+		var name Node = n.Name
+
+		p.write(label)
+		p.indent()
+		p.writepad("Name: ")
+		p.format(&name)
+		p.writepad("Value: ")
+		p.format(&n.Value)
+		p.writepad(line, column, let)
+		p.outdent()
+		p.writepad(close)
+	case Program:
+		label := "Program{" + newline
+
+		p.write(label)
+		p.indent()
+		if len(n.Stmts) == 0 {
+			p.writepad("Stmts: []" + newline)
+		} else {
+			p.writepad("Stmts: [" + newline)
+			p.indent()
+			for _, stmt := range n.Stmts {
+				p.writepad("") // pad each statement
+				p.format(&stmt)
+			}
+			p.outdent()
+			p.writepad("]" + newline)
+		}
+		p.outdent()
+		p.writepad(close)
+	case Lambda:
+		label := "Lambda{" + newline
+		line := li(n.Line)
+		column := co(n.Column)
+		lparen := fmt.Sprintf("Lparen: %s%s", n.Lparen, newline)
+		rparen := fmt.Sprintf("Rparen: %s%s", n.Rparen, newline)
+
+		p.write(label)
+		p.indent()
+		p.formatParams(n.Params)
+		p.writepad("Body: ")
+		p.format(&n.Body)
+		p.writepad(line, column, lparen, rparen)
+		p.outdent()
+		p.writepad(close)
+	case FuncDef:
+		label := "FuncDef{" + newline
+		line := li(n.Line)
+		column := co(n.Column)
+		lparen := fmt.Sprintf("Lparen: %s%s", n.Lparen, newline)
+		rparen := fmt.Sprintf("Rparen: %s%s", n.Rparen, newline)
+		var name Node = n.Name
+
+		p.write(label)
+		p.indent()
+		p.writepad("Name: ")
+		p.format(&name)
+		p.formatParams(n.Params)
+		p.writepad("Body: ")
+		p.format(&n.Body)
+		p.writepad(line, column, lparen, rparen)
+		p.outdent()
+		p.writepad(close)
+	case List:
+		label := "List{" + newline
+		lbrack := fmt.Sprintf("Lbrack: %s%s", n.Lbrack, newline)
+		rbrack := fmt.Sprintf("Rbrack: %s%s", n.Rbrack, newline)
+
+		p.write(label)
+		p.indent()
+		p.formatNodes("Elems", n.Elems)
+		p.writepad(lbrack, rbrack)
+		p.outdent()
+		p.writepad(close)
+	case Matrix:
+		label := "Matrix{" + newline
+		lbrack := fmt.Sprintf("Lbrack: %s%s", n.Lbrack, newline)
+		rbrack := fmt.Sprintf("Rbrack: %s%s", n.Rbrack, newline)
+
+		p.write(label)
+		p.indent()
+		p.writepad("Rows: [" + newline)
+		p.indent()
+		for _, row := range n.Rows {
+			p.formatNodes("", row)
+		}
+		p.outdent()
+		p.writepad("]" + newline)
+		p.writepad(lbrack, rbrack)
+		p.outdent()
+		p.writepad(close)
+	case Index:
+		label := "Index{" + newline
+		line := li(n.Line)
+		column := co(n.Column)
+		rbrack := fmt.Sprintf("Rbrack: %s%s", n.Rbrack, newline)
+
+		p.write(label)
+		p.indent()
+		p.writepad("X: ")
+		p.format(&n.X)
+		p.formatNodes("Indices", n.Indices)
+		p.writepad(line, column, rbrack)
 		p.outdent()
 		p.writepad(close)
 	case Call:
@@ -134,6 +350,47 @@ func (p *printer) format(n *Node) {
 	}
 }
 
+// Formats a list of nodes, labeled by "field" unless it is empty.
+// A nil node, the index ":", formats as a colon.
+func (p *printer) formatNodes(field string, nodes []Node) {
+	label := ""
+	if field != "" {
+		label = field + ": "
+	}
+	if len(nodes) == 0 {
+		p.writepad(label + "[]" + newline)
+		return
+	}
+	p.writepad(label + "[" + newline)
+	p.indent()
+	for _, n := range nodes {
+		if n == nil {
+			p.writepad(":" + newline)
+			continue
+		}
+		p.writepad("") // pad each node
+		p.format(&n)
+	}
+	p.outdent()
+	p.writepad("]" + newline)
+}
+
+func (p *printer) formatParams(params []Symbol) {
+	if len(params) == 0 {
+		p.writepad("Params: []" + newline)
+		return
+	}
+	p.writepad("Params: [" + newline)
+	p.indent()
+	for _, param := range params {
+		var n Node = param
+		p.writepad("") // pad each parameter
+		p.format(&n)
+	}
+	p.outdent()
+	p.writepad("]" + newline)
+}
+
 // Inputs a pointer to a Node and outputs a formatted string of that Node.
 func Format(n *Node) string {
 	var b strings.Builder
@@ -146,3 +403,508 @@ func Format(n *Node) string {
 	p.format(n)
 	return p.print()
 }
+
+// Inputs the source text of a failed parse and the error it produced.
+// Outputs each offending line, a caret beneath the start of each error,
+// tildes beneath the remainder of its span, and a numbered list of
+// messages. Accepts an *Error, an ErrorList, or any error wrapping either.
+// Other errors are output as their message alone.
+//
+//	1 ÷ * 0
+//	    ^
+//	1. undefined prefix operation "*" line:1 column:5
+func FormatError(src string, err error) string {
+	var errs ErrorList
+	var e *Error
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &errs):
+	case errors.As(err, &e):
+		errs = ErrorList{e}
+	default:
+		return err.Error() + newline
+	}
+
+	lines := strings.Split(src, newline)
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	// Marks beneath each line, indexed by line then column. Columns count runes.
+	marks := make(map[int][]rune)
+	mark := func(line, column int, m rune) {
+		row := marks[line]
+		for len(row) < column {
+			row = append(row, 0)
+		}
+		if row[column-1] != '^' {
+			row[column-1] = m
+		}
+		marks[line] = row
+	}
+	for _, e := range errs {
+		mark(e.Pos.Line, e.Pos.Column, '^')
+		line, column := e.Pos.Line, e.Pos.Column+1
+		for line < e.End.Line || (line == e.End.Line && column < e.End.Column) {
+			if line > len(lines) {
+				break
+			}
+			if column > utf8.RuneCountInString(lines[line-1]) {
+				line, column = line+1, 1
+				continue
+			}
+			mark(line, column, '~')
+			column += 1
+		}
+	}
+
+	numbered := make([]int, 0, len(marks))
+	for line := range marks {
+		numbered = append(numbered, line)
+	}
+	sort.Ints(numbered)
+	// Label lines only if the source spans more than one.
+	label := func(line int) string { return "" }
+	blank := ""
+	if len(lines) > 1 {
+		width := len(strconv.Itoa(len(lines)))
+		label = func(line int) string { return fmt.Sprintf("%*d | ", width, line) }
+		blank = strings.Repeat(" ", width) + " | "
+	}
+
+	var b strings.Builder
+	for _, line := range numbered {
+		var text []rune
+		if line >= 1 && line <= len(lines) {
+			text = []rune(lines[line-1])
+		}
+		b.WriteString(label(line))
+		b.WriteString(string(text))
+		b.WriteString(newline)
+		// Match tabs in the source so that marks align beneath their runes.
+		underline := make([]rune, len(marks[line]))
+		for i, m := range marks[line] {
+			switch {
+			case m != 0:
+				underline[i] = m
+			case i < len(text) && text[i] == '\t':
+				underline[i] = '\t'
+			default:
+				underline[i] = ' '
+			}
+		}
+		b.WriteString(blank)
+		b.WriteString(string(underline))
+		b.WriteString(newline)
+	}
+	for i, e := range errs {
+		fmt.Fprintf(&b, "%d. %s%s", i+1, e, newline)
+	}
+	return b.String()
+}
+
+// Configures "Print". The zero value reads binding powers from the
+// default grammar, keeps implied multiplication and parenthesized groups,
+// and spells multiplication "*".
+type PrintConfig struct {
+	Grammar  *Grammar // Binding powers and associativity. Nil means the default grammar.
+	Explicit bool     // Writes implied multiplication as "11 * x" rather than "11x".
+	Times    bool     // Spells multiplication "×" rather than "*".
+	Unparen  bool     // Drops the parentheses of Paren nodes wherever binding powers allow.
+
+	bracket bool // If true, output is directly within brackets, where whitespace separates elements.
+}
+
+// Maps each operator to the lexeme whose binding powers it takes.
+var operators = map[string]LexType{
+	"=":   lexer.Equal,
+	"≠":   lexer.NotEqual,
+	"<":   lexer.Less,
+	">":   lexer.Greater,
+	"<=":  lexer.LessEqual,
+	">=":  lexer.GreaterEqual,
+	"+":   lexer.Add,
+	"-":   lexer.Sub,
+	"*":   lexer.Mul,
+	"/":   lexer.Div,
+	"^":   lexer.Pow,
+	"and": lexer.And,
+	"or":  lexer.Or,
+	"not": lexer.Not,
+	"!":   lexer.Factorial,
+	"%":   lexer.Percent,
+	"'":   lexer.Prime,
+	"?":   lexer.Question,
+	"if":  lexer.If, // No binding power, but a keyword all the same.
+	"let": lexer.Let,
+}
+
+// Outputs the lexeme of operator "op". Unknown operators map to EOF,
+// which has no binding power.
+func lexeme(op string) LexType {
+	if t, ok := operators[op]; ok {
+		return t
+	}
+	return lexer.EOF
+}
+
+// Inputs a Node and outputs it as infix source text. Paren nodes keep
+// their parentheses. Elsewhere, parentheses are added only where binding
+// powers and associativity demand. Parsing the output yields the same
+// tree, positions aside.
+//
+//	sum(7, 11x)
+func Print(n Node) string {
+	return PrintConfig{}.Print(n)
+}
+
+// Like "Print" but configured by "c". With "Explicit" set, implied
+// multiplication reparses as Binary rather than ImpliedBinary. With
+// "Unparen" set, the output reparses without redundant Paren nodes.
+func (c PrintConfig) Print(n Node) string {
+	if c.Grammar == nil {
+		c.Grammar = grammar
+	}
+	return c.expr(n, 0, 0)
+}
+
+// Outputs "n" as the parser would read it with right binding power "rbp",
+// followed by an operator of left binding power "follow". Parenthesizes
+// "n" where the parser would otherwise group it differently.
+func (c PrintConfig) expr(n Node, rbp, follow int) string {
+	switch n := n.(type) {
+	case Bad:
+		return "<bad>"
+	case Number:
+		if n.Raw != "" {
+			return n.Raw
+		}
+		return strconv.FormatFloat(n.Value, 'f', -1, 64)
+	case String:
+		if n.Raw != "" {
+			return n.Raw
+		}
+		return strconv.Quote(n.Value)
+	case Symbol:
+		return n.Value
+	case Boolean:
+		return strconv.FormatBool(n.Value)
+	case Unary:
+		// The operand of a prefix operator extends over any operator
+		// that binds tighter than the prefix.
+		bp := c.Grammar.prefix[lexeme(n.Op)]
+		if follow > bp {
+			return c.paren(n)
+		}
+		// A keyword operator, such as "not", is set apart from its operand.
+		if r, _ := utf8.DecodeLastRuneInString(n.Op); unicode.IsLetter(r) {
+			return n.Op + " " + c.expr(n.X, bp, follow)
+		}
+		return n.Op + c.expr(n.X, bp, follow)
+	case Postfix:
+		// A postfix operator binding no tighter than its context would
+		// apply to the enclosing expression.
+		bp := c.Grammar.bind[lexeme(n.Op)]
+		if rbp > 0 && bp <= rbp {
+			return c.paren(n)
+		}
+		return c.expr(n.X, rbp, bp) + n.Op
+	case Binary:
+		op := n.Op
+		if op == "*" && c.Times {
+			op = "×"
+		}
+		return c.infix(n, lexeme(n.Op), " "+op+" ", n.X, n.Y, rbp, follow)
+	case ImpliedBinary:
+		if c.Explicit {
+			op := "*"
+			if c.Times {
+				op = "×"
+			}
+			return c.infix(n, lexer.ImpMul, " "+op+" ", n.X, n.Y, rbp, follow)
+		}
+		return c.infix(n, lexer.ImpMul, "", n.X, n.Y, rbp, follow)
+	case Comparison:
+		if len(n.Ops) == 0 || len(n.Operands) != len(n.Ops)+1 {
+			return ""
+		}
+		// Operators of a chain share a binding power. Any operand that
+		// is itself a chain of that power is parenthesized.
+		bp := c.Grammar.bind[lexeme(n.Ops[0])]
+		if (rbp > 0 || follow > 0) && (bp <= rbp || follow > bp) {
+			return c.paren(n)
+		}
+		var b strings.Builder
+		for i, x := range n.Operands {
+			switch {
+			case i == 0:
+				b.WriteString(c.expr(x, rbp, bp+1))
+			case i == len(n.Ops):
+				b.WriteString(" " + n.Ops[i-1] + " " + c.expr(x, bp, follow))
+			default:
+				b.WriteString(" " + n.Ops[i-1] + " " + c.expr(x, bp, bp+1))
+			}
+		}
+		return b.String()
+	case Conditional:
+		if n.Keyword {
+			// Like a prefix operator of no power, the "else" branch
+			// extends over any operator that follows.
+			if follow > 0 {
+				return c.paren(n)
+			}
+			return "if " + c.expr(n.Cond, 0, 0) + " then " + c.expr(n.Then, 0, 0) +
+				" else " + c.expr(n.Else, 0, 0)
+		}
+		// Like a right-associative operator whose middle operand is
+		// delimited by "?" and ":".
+		bp := c.Grammar.bind[lexer.Question]
+		if (rbp > 0 || follow > 0) && (bp <= rbp || follow > bp-1) {
+			return c.paren(n)
+		}
+		return c.expr(n.Cond, rbp, bp) + " ? " + c.expr(n.Then, 0, 0) + " : " + c.expr(n.Else, bp-1, follow)
+	case Assign:
+		s := n.Name.Value + " = " + c.expr(n.Value, 0, 0)
+		if n.Let.Line > 0 {
+			return "let " + s
+		}
+		return s
+	case Program:
+		stmts := make([]string, len(n.Stmts))
+		for i, stmt := range n.Stmts {
+			stmts[i] = c.expr(stmt, 0, 0)
+			// An equality of a name standing alone would read as an
+			// assignment.
+			x := stmt
+			for p, ok := x.(Paren); ok && c.Unparen; p, ok = x.(Paren) {
+				x = p.X
+			}
+			if b, ok := x.(Binary); ok && b.Op == "=" {
+				if _, ok := b.X.(Symbol); ok {
+					stmts[i] = c.paren(b)
+				}
+			}
+		}
+		return strings.Join(stmts, "; ")
+	case Lambda:
+		// Like a prefix operator of no power, the body extends over any
+		// operator that follows.
+		if follow > 0 {
+			return c.paren(n)
+		}
+		if len(n.Params) == 1 && n.Lparen.Line == 0 {
+			return n.Params[0].Value + " -> " + c.expr(n.Body, 0, 0)
+		}
+		return params(n.Params) + " -> " + c.expr(n.Body, 0, 0)
+	case FuncDef:
+		// Like a right operand of least power, the body extends over any
+		// operator that follows.
+		if rbp >= c.Grammar.bind[lexer.Define] || follow > 0 {
+			return c.paren(n)
+		}
+		return n.Name.Value + params(n.Params) + " := " + c.expr(n.Body, 0, 0)
+	case Call:
+		callee := c.expr(n.Callee, 0, 0)
+		// Only a symbol, its derivative, a string or boolean literal, a
+		// list or matrix, an index, a call, or a parenthesized lambda is
+		// called rather than multiplied by a parenthesis that follows.
+		x := n.Callee
+		for p, ok := x.(Paren); ok && c.Unparen; p, ok = x.(Paren) {
+			x = p.X
+		}
+		if _, ok := x.(Paren); !ok && !callable(x) {
+			callee = c.paren(n.Callee)
+		}
+		args := make([]string, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = c.within(false).expr(arg, 0, 0)
+		}
+		return callee + "(" + strings.Join(args, ", ") + ")"
+	case List:
+		return "[" + c.elems(n.Elems) + "]"
+	case Matrix:
+		rows := make([]string, len(n.Rows))
+		for i, row := range n.Rows {
+			rows[i] = c.elems(row)
+		}
+		// A trailing semicolon marks a matrix of one row.
+		if len(rows) == 1 {
+			return "[" + rows[0] + ";]"
+		}
+		return "[" + strings.Join(rows, "; ") + "]"
+	case Index:
+		// Like a postfix operator, binds its operand tighter than any
+		// operator within it.
+		bp := c.Grammar.bind[lexer.OpenBracket]
+		if rbp > 0 && bp <= rbp {
+			return c.paren(n)
+		}
+		indices := make([]string, len(n.Indices))
+		for i, x := range n.Indices {
+			if x == nil {
+				indices[i] = ":"
+			} else {
+				indices[i] = c.within(true).expr(x, 0, 0)
+			}
+		}
+		return c.expr(n.X, rbp, bp) + "[" + strings.Join(indices, ", ") + "]"
+	case Paren:
+		if c.Unparen {
+			return c.expr(n.X, rbp, follow)
+		}
+		return c.paren(n.X)
+	default:
+		return ""
+	}
+}
+
+func (c PrintConfig) paren(n Node) string {
+	return "(" + c.within(false).expr(n, 0, 0) + ")"
+}
+
+// Outputs "c" configured for output directly within brackets, if
+// "bracket" is true, or else within parentheses.
+func (c PrintConfig) within(bracket bool) PrintConfig {
+	c.bracket = bracket
+	return c
+}
+
+// Outputs the elements of a list or a row of a matrix.
+func (c PrintConfig) elems(nodes []Node) string {
+	elems := make([]string, len(nodes))
+	for i, x := range nodes {
+		elems[i] = c.within(true).expr(x, 0, 0)
+	}
+	return strings.Join(elems, ", ")
+}
+
+// Outputs parameters "ps" as a parenthesized list.
+func params(ps []Symbol) string {
+	names := make([]string, len(ps))
+	for i, p := range ps {
+		names[i] = p.Value
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+// Outputs binary operation "n" with operands "x" and "y" joined by "op".
+// An empty "op" juxtaposes the operands.
+func (c PrintConfig) infix(n Node, t LexType, op string, x, y Node, rbp, follow int) string {
+	bp := c.Grammar.bind[t]
+	r := bp
+	// An operator of equal power to the left of a non-associative
+	// operator would form a chain.
+	l := bp
+	switch c.Grammar.assoc[t] {
+	case Right:
+		r = bp - 1
+	case NonAssoc:
+		l = bp + 1
+	}
+	// An operator binding no tighter than its context would be read
+	// by the enclosing expression. An operator following "n" that binds
+	// tighter than "n" would steal its right operand.
+	if (rbp > 0 || follow > 0) && (bp <= rbp || follow > r) {
+		return c.paren(n)
+	}
+	left := c.expr(x, rbp, l)
+	right := c.expr(y, r, follow)
+	if op != "" {
+		return left + op + right
+	}
+	// The lexer implies multiplication only after a number, a closing
+	// parenthesis, a factorial or percent sign, or a symbol followed by
+	// space and another word. A symbol followed by a parenthesis is a call.
+	last, _ := utf8.DecodeLastRuneInString(left)
+	first, _ := utf8.DecodeRuneInString(right)
+	if !c.bracket && isAlphaNumeric(last) && endsInSymbol(x) && unicode.IsLetter(first) && !keyword(right) {
+		return left + " " + right
+	}
+	if last != ')' && last != '!' && last != '%' && !endsInNumber(x) {
+		left = c.paren(x)
+		last = ')'
+	}
+	implied := (unicode.IsLetter(first) && !keyword(right)) || first == '('
+	if last == ')' {
+		implied = implied || unicode.IsDigit(first)
+	}
+	if !implied {
+		right = c.paren(y)
+	}
+	if strings.HasPrefix(right, "(") && endsInCall(x) {
+		// A call directly followed by a parenthesis is called again.
+		return left + " " + right
+	}
+	return left + right
+}
+
+// Reports whether text "s" begins with a keyword operator, such as "not",
+// which the lexer never multiplies by juxtaposition.
+func keyword(s string) bool {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	_, ok := operators[s[:i]]
+	return ok
+}
+
+// Reports whether the text of "n", written without parentheses,
+// ends in a symbol.
+func endsInSymbol(n Node) bool {
+	switch n := n.(type) {
+	case Symbol:
+		return true
+	case Unary:
+		return endsInSymbol(n.X)
+	case Binary:
+		return endsInSymbol(n.Y)
+	case ImpliedBinary:
+		return endsInSymbol(n.Y)
+	case Comparison:
+		return len(n.Operands) > 0 && endsInSymbol(n.Operands[len(n.Operands)-1])
+	}
+	return false
+}
+
+// Reports whether the text of "n", written without parentheses,
+// ends in a call.
+func endsInCall(n Node) bool {
+	switch n := n.(type) {
+	case Call:
+		return true
+	case Unary:
+		return endsInCall(n.X)
+	case Binary:
+		return endsInCall(n.Y)
+	case ImpliedBinary:
+		return endsInCall(n.Y)
+	case Comparison:
+		return len(n.Operands) > 0 && endsInCall(n.Operands[len(n.Operands)-1])
+	}
+	return false
+}
+
+func isAlphaNumeric(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// Reports whether the text of "n", written without parentheses,
+// ends in a number.
+func endsInNumber(n Node) bool {
+	switch n := n.(type) {
+	case Number:
+		return true
+	case Unary:
+		return endsInNumber(n.X)
+	case Binary:
+		return endsInNumber(n.Y)
+	case ImpliedBinary:
+		return endsInNumber(n.Y)
+	case Comparison:
+		return len(n.Operands) > 0 && endsInNumber(n.Operands[len(n.Operands)-1])
+	}
+	return false
+}
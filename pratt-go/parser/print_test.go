@@ -0,0 +1,326 @@
+package parser
+
+import "testing"
+
+func TestFormatError(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		expect string
+	}{
+		{
+			"prefix",
+			"1 ÷ * 0",
+			"1 ÷ * 0\n" +
+				"    ^\n" +
+				"1. undefined prefix operation \"*\" line:1 column:5\n",
+		},
+		{
+			"unused",
+			"2 × 3 ≠ 4 5 + 6",
+			"2 × 3 ≠ 4 5 + 6\n" +
+				"          ^\n" +
+				"1. unused tokens following expression line:1 column:11\n",
+		},
+		{
+			"eof",
+			"1 +",
+			"1 +\n" +
+				"   ^\n" +
+				"1. incomplete expression, unexpected <EOF> line:1 column:4\n",
+		},
+		{
+			"tabs",
+			"1 +\n\t\t* 2",
+			"2 | \t\t* 2\n" +
+				"  | \t\t^\n" +
+				"1. undefined prefix operation \"*\" line:2 column:3\n",
+		},
+		{
+			"lines",
+			"f(x, 2 +\n3) := 1",
+			"1 | f(x, 2 +\n" +
+				"  |      ^~~\n" +
+				"2 | 3) := 1\n" +
+				"  | ~\n" +
+				"1. expected a parameter name, got 2 + 3 line:1 column:6\n",
+		},
+		{
+			"alternate and",
+			"∧ 1",
+			"∧ 1\n" +
+				"^\n" +
+				"1. undefined prefix operation \"and\" line:1 column:1\n",
+		},
+		{
+			"alternate less or equal",
+			"≤ 1",
+			"≤ 1\n" +
+				"^\n" +
+				"1. undefined prefix operation \"<=\" line:1 column:1\n",
+		},
+		{
+			"alternate not",
+			"1 ¬ 2",
+			"1 ¬ 2\n" +
+				"  ^\n" +
+				"1. unused tokens following expression line:1 column:3\n",
+		},
+		{
+			"alternate not equal",
+			"!= 1",
+			"!= 1\n" +
+				"^~\n" +
+				"1. undefined prefix operation \"≠\" line:1 column:1\n",
+		},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.text)
+		result := FormatError(test.text, err)
+		if result != test.expect {
+			t.Errorf("TestFormatError (%s) failed. Expected:\n%s\nGot:\n%s", test.name, test.expect, result)
+		}
+	}
+}
+
+func TestFormatErrorList(t *testing.T) {
+	text := "1 + * 7\n+ (2 $) +"
+	expect := "1 | 1 + * 7\n" +
+		"  |     ^\n" +
+		"2 | + (2 $) +\n" +
+		"  |      ^   ^\n" +
+		"1. undefined prefix operation \"*\" line:1 column:5\n" +
+		"2. unexpected character: \"$\" line:2 column:6\n" +
+		"3. incomplete expression, unexpected <EOF> line:2 column:10\n"
+	_, errs := ParseAll(text)
+	result := FormatError(text, errs)
+	if result != expect {
+		t.Errorf("TestFormatErrorList failed. Expected:\n%s\nGot:\n%s", expect, result)
+	}
+}
+
+func TestFormatConditional(t *testing.T) {
+	node, _ := Parse("if x then 1 else 2")
+	expect := `Conditional{
+    Cond: Symbol{
+        Value:  "x"
+        Line:   1
+        Column: 4
+    }
+    Then: Number{
+        Value:  1
+        Line:   1
+        Column: 11
+    }
+    Else: Number{
+        Value:  2
+        Line:   1
+        Column: 18
+    }
+    Keyword: true
+    Line:   1
+    Column: 1
+    ThenPos: line:1 column:6
+    ElsePos: line:1 column:13
+}
+`
+	if result := Format(&node); result != expect {
+		t.Errorf("TestFormatConditional failed. Expected: %s, Got: %s", expect, result)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{"", ""},
+		{"sum(7, 11x)", "sum(7, 11x)"},
+		{"1+2*3", "1 + 2 * 3"},
+		{"(1 + 2) * 3", "(1 + 2) * 3"},
+		{"1 - (2 - 3)", "1 - (2 - 3)"},
+		{"(1 - 2) - 3", "1 - 2 - 3"},
+		{"2 ^ (3 ^ 4)", "2 ^ 3 ^ 4"},
+		{"(2 ^ 3) ^ 4", "(2 ^ 3) ^ 4"},
+		{"-x^2", "-x ^ 2"},
+		{"(-x)^2", "(-x) ^ 2"},
+		{"-(x + 1)", "-(x + 1)"},
+		{"-(-x)", "--x"},
+		{"2 ^ -x", "2 ^ -x"},
+		{"(a / -x) * b", "a / (-x) * b"},
+		{"a / -(x * b)", "a / -x * b"},
+		{"7 × 2 ÷ 5", "7 * 2 / 5"},
+		{"x = (y ≠ z)", "x = (y ≠ z)"},
+		{"(x)(y)", "(x)y"},
+		{"2(3)", "2(3)"},
+		{"2(-x)", "2(-x)"},
+		{"(1 + 2)3", "(1 + 2)3"},
+		{"2(x + 1)", "2(x + 1)"},
+		{"2x^2", "2x ^ 2"},
+		{"(2x)^2", "(2x) ^ 2"},
+		{"3.14 r (r)", "3.14r(r)"},
+		{"f((x))", "f(x)"},
+		{"0 ≤ x < 10", "0 <= x < 10"},
+		{"(a < b) < c", "(a < b) < c"},
+		{"a < (b < c)", "a < (b < c)"},
+		{"a = b = c", "a = b = c"},
+		{"x + 1 >= 2y", "x + 1 >= 2y"},
+		{"a ∧ b ∨ ¬c", "a and b or not c"},
+		{"(a or b) and c", "(a or b) and c"},
+		{"!(a = b)", "not a = b"},
+		{"(!a) = b", "(not a) = b"},
+		{"2(not x)", "2(not x)"},
+		{"true && false", "true and false"},
+		{"2^3!", "2 ^ 3!"},
+		{"(2^3)!", "(2 ^ 3)!"},
+		{"(-3)!", "(-3)!"},
+		{"-3!", "-3!"},
+		{"3!x", "3!x"},
+		{"(x!)(y)", "x!y"},
+		{"(x!)y", "x!y"},
+		{"(x%)2", "x%(2)"},
+		{"f''(x)", "f''(x)"},
+		{"(n + 1)!%", "(n + 1)!%"},
+		{"a ? b : c ? d : e", "a ? b : c ? d : e"},
+		{"(a ? b : c) ? d : e", "(a ? b : c) ? d : e"},
+		{"a ? b ? c : d : e", "a ? b ? c : d : e"},
+		{"(a ? b : c) + 1", "(a ? b : c) + 1"},
+		{"x or y ? 1 : 2", "x or y ? 1 : 2"},
+		{"if a then b else c + 1", "if a then b else c + 1"},
+		{"(if a then b else c) + 1", "(if a then b else c) + 1"},
+		{"2(if a then b else c)", "2(if a then b else c)"},
+		{"a ? if b then c else d : e", "a ? if b then c else d : e"},
+		{"x ↦ x + 1", "x -> x + 1"},
+		{"(x) -> x", "(x) -> x"},
+		{"((x, y) -> x) + 1", "((x, y) -> x) + 1"},
+		{"1 + (() -> 1)", "1 + () -> 1"},
+		{"(x -> 2x)(3)", "(x -> 2x)(3)"},
+		{"k(1)(2)(3, 4)", "k(1)(2)(3, 4)"},
+		{"f(x) (y)", "f(x)y"},
+		{"f(x) (-y)", "f(x) (-y)"},
+		{"2f(x) (y + 1)", "2f(x) (y + 1)"},
+		{"f(x -> x, 2)", "f(x -> x, 2)"},
+		{"f(x) := (g(y) := x)", "f(x) := g(y) := x"},
+		{"(f(x) := x) + 1", "(f(x) := x) + 1"},
+		{"a ? b : (f(x) := x)", "a ? b : (f(x) := x)"},
+		{"[1 2 3]", "[1, 2, 3]"},
+		{"[1 2; 3 4]", "[1, 2; 3, 4]"},
+		{"[1 2;]", "[1, 2;]"},
+		{"[[1], []]", "[[1], []]"},
+		{"[(pi r) 2]", "[(pi)r, 2]"},
+		{"[pi r]", "[pi, r]"},
+		{"[2 (x + 1)]", "[2, x + 1]"},
+		{"(a + b)[1]", "(a + b)[1]"},
+		{"(v[1])[2]", "v[1][2]"},
+		{"m[:, 2]", "m[:, 2]"},
+		{"-v[1]", "-v[1]"},
+		{"v[1]!", "v[1]!"},
+		{"f(x)[1]", "f(x)[1]"},
+		{"(pi r)[1]", "(pi r)[1]"},
+		{`"Ada" + 'Lovelace'`, `"Ada" + 'Lovelace'`},
+		{`upper("\u00e9")`, `upper("\u00e9")`},
+		{`["a" "b"]`, `["a", "b"]`},
+	}
+	for _, test := range tests {
+		node, err := Parse(test.text)
+		if err != nil {
+			t.Fatalf("TestPrint failed for %q. Got: %s", test.text, err)
+		}
+		result := PrintConfig{Unparen: true}.Print(node)
+		if result != test.expect {
+			t.Errorf("TestPrint failed for %q. Expected: %s, Got: %s", test.text, test.expect, result)
+		}
+		reparsed, err := Parse(result)
+		if err != nil {
+			t.Errorf("TestPrint failed for %q. Reparse error: %s", test.text, err)
+			continue
+		}
+		if !Equal(unparen(node), unparen(reparsed), IgnorePositions()) {
+			t.Errorf("TestPrint failed for %q. Expected: %s, Got: %s", test.text, node, reparsed)
+		}
+	}
+}
+
+func TestPrintProgram(t *testing.T) {
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{"r = 3\narea = pi r^2\n\narea", "r = 3; area = pi r ^ 2; area"},
+		{"let x = 1; (x = 1)", "let x = 1; (x = 1)"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		node, err := ParseProgram(test.text)
+		if err != nil {
+			t.Fatalf("TestPrintProgram failed for %q. Got: %s", test.text, err)
+		}
+		result := PrintConfig{Unparen: true}.Print(node)
+		if result != test.expect {
+			t.Errorf("TestPrintProgram failed for %q. Expected: %s, Got: %s", test.text, test.expect, result)
+		}
+		reparsed, err := ParseProgram(result)
+		if err != nil || !Equal(unparen(node), unparen(reparsed), IgnorePositions()) {
+			t.Errorf("TestPrintProgram failed for %q. Expected: %s, Got: %v %v", test.text, node, reparsed, err)
+		}
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	node, _ := Parse("2(y + 1)x * 3")
+	tests := []struct {
+		config PrintConfig
+		expect string
+	}{
+		{PrintConfig{}, "2(y + 1)x * 3"},
+		{PrintConfig{Times: true}, "2(y + 1)x × 3"},
+		{PrintConfig{Explicit: true}, "2 * (y + 1) * x * 3"},
+		{PrintConfig{Explicit: true, Times: true}, "2 × (y + 1) × x × 3"},
+	}
+	for _, test := range tests {
+		if result := test.config.Print(node); result != test.expect {
+			t.Errorf("TestPrintConfig failed. Expected: %s, Got: %s", test.expect, result)
+		}
+	}
+}
+
+func TestPrintGrammar(t *testing.T) {
+	g := DefaultGrammar()
+	g.Infix(LexSub, 20, Right)
+	node, _ := Parse("(1 - 2) - 3")
+	expect := "(1 - 2) - 3"
+	if result := (PrintConfig{Grammar: g}).Print(node); result != expect {
+		t.Errorf("TestPrintGrammar failed. Expected: %s, Got: %s", expect, result)
+	}
+	node, _ = New(g).Parse("1 - 2 - 3")
+	expect = "1 - 2 - 3"
+	if result := (PrintConfig{Grammar: g}).Print(node); result != expect {
+		t.Errorf("TestPrintGrammar failed. Expected: %s, Got: %s", expect, result)
+	}
+}
+
+func TestPrintRoundTrip(t *testing.T) {
+	// Calls of literals, which evaluation rejects, still print as calls.
+	calls := []string{`"a"(2.5)`, "true()", "[1, 2](3)", "[1 2;](3)", "v[1](2)"}
+	for _, test := range append(calls, fixtures...) {
+		node, _ := Parse(test)
+		text := Print(node)
+		result, err := Parse(text)
+		if err != nil {
+			t.Fatalf("TestPrintRoundTrip failed for %q. Got: %s", text, err)
+		}
+		if !Equal(node, result, IgnorePositions()) {
+			t.Errorf("TestPrintRoundTrip failed for %q. Expected: %s, Got: %s", text, node, result)
+		}
+	}
+}
+
+// Removes every Paren node from "n", keeping its contents.
+func unparen(n Node) Node {
+	return Apply(n, nil, func(c *Cursor) bool {
+		if p, ok := c.Node().(Paren); ok {
+			c.Replace(p.X)
+		}
+		return true
+	})
+}
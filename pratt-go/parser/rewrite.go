@@ -0,0 +1,299 @@
+package parser
+
+// An ApplyFunc is invoked by "Apply" for each node, before and after
+// the node's children are traversed. See "Apply" for the interpretation
+// of the return value.
+type ApplyFunc func(*Cursor) bool
+
+// Traverses an AST recursively, starting with "root", and calls "pre"
+// and "post" for each node as follows:
+//
+// If "pre" is not nil, it is called for each node before the node's
+// children are traversed (pre-order). If "pre" returns false, no children
+// are traversed, and "post" is not called for that node.
+//
+// If "post" is not nil, and a prior call of "pre" did not return false,
+// "post" is called for each node after its children are traversed
+// (post-order). If "post" returns false, traversal is terminated and
+// "Apply" returns immediately.
+//
+// Only fields that refer to AST nodes are considered children. Children
+// are traversed in source order, and nil children are skipped.
+//
+// Since nodes are values, "Apply" never modifies the tree it is given.
+// It outputs a copy of "root" rebuilt with every modification made
+// through a Cursor, including those made before termination.
+func Apply(root Node, pre, post ApplyFunc) Node {
+	a := &applier{pre: pre, post: post}
+	return a.apply(nil, "", nil, root)
+}
+
+// A Cursor describes a node encountered during "Apply". Information
+// about the node and its parent is available from the "Node", "Parent",
+// "Name", and "Index" methods. The node may be modified through
+// "Replace", "Delete", "InsertBefore", and "InsertAfter".
+type Cursor struct {
+	parent Node
+	name   string
+	iter   *iterator // Non-nil if the node is an element of a slice.
+	node   Node
+}
+
+// Tracks edits to the slice element under a Cursor.
+type iterator struct {
+	index   int    // Index of the element within the rebuilt slice.
+	before  []Node // Nodes inserted before the element.
+	after   []Node // Nodes inserted after the element.
+	deleted bool
+	fixed   bool // If true, the slice's length cannot change.
+}
+
+// Outputs the current node.
+func (c *Cursor) Node() Node { return c.node }
+
+// Outputs the parent of the current node, or nil for the root. Reflects
+// modifications to the node's earlier siblings, but not to the node
+// itself or its later siblings.
+func (c *Cursor) Parent() Node { return c.parent }
+
+// Outputs the name of the parent field that contains the current node:
+// such as "X", "Callee", "Args", or "Stmts". Outputs "" for the root.
+func (c *Cursor) Name() string { return c.name }
+
+// Outputs the index of the current node within its slice, such as
+// "Call.Args", counting nodes already inserted or deleted. Outputs a
+// negative value if the current node is not part of a slice.
+func (c *Cursor) Index() int {
+	if c.iter == nil {
+		return -1
+	}
+	return c.iter.index + len(c.iter.before)
+}
+
+// Replaces the current node with "n". The replacement's children are
+// traversed if "Replace" is called from "pre".
+func (c *Cursor) Replace(n Node) {
+	c.node = n
+}
+
+// Deletes the current node from its containing slice. Neither its
+// children nor "post" are traversed if "Delete" is called from "pre".
+// Panics if the current node is not part of a slice whose length may
+// change. "Comparison.Operands" must keep pace with its operators.
+func (c *Cursor) Delete() {
+	c.resize("Delete")
+	c.iter.deleted = true
+}
+
+// Inserts "n" after the current node in its containing slice. Each call
+// inserts immediately after the current node, before any nodes inserted
+// earlier. "Apply" does not walk "n". Panics as "Delete" does.
+func (c *Cursor) InsertAfter(n Node) {
+	c.resize("InsertAfter")
+	c.iter.after = append([]Node{n}, c.iter.after...)
+}
+
+// Inserts "n" before the current node in its containing slice. "Apply"
+// does not walk "n". Panics as "Delete" does.
+func (c *Cursor) InsertBefore(n Node) {
+	c.resize("InsertBefore")
+	c.iter.before = append(c.iter.before, n)
+}
+
+// Panics unless the current node's slice may change length.
+func (c *Cursor) resize(method string) {
+	if c.iter == nil {
+		panic(method + " node not contained in slice")
+	}
+	if c.iter.fixed {
+		panic(method + " node in fixed-length slice " + c.name)
+	}
+}
+
+type applier struct {
+	pre, post ApplyFunc
+	done      bool // Set once "post" terminates traversal.
+}
+
+// Visits node "n", found in field "name" of "parent". Outputs the node
+// that takes its place.
+func (a *applier) apply(parent Node, name string, iter *iterator, n Node) Node {
+	if n == nil || a.done {
+		return n
+	}
+	c := &Cursor{
+		parent: parent,
+		name:   name,
+		iter:   iter,
+		node:   n,
+	}
+	if a.pre != nil && !a.pre(c) {
+		return c.node
+	}
+	if iter != nil && iter.deleted {
+		return c.node
+	}
+	c.node = a.children(c.node)
+	if a.done {
+		return c.node
+	}
+	if a.post != nil && !a.post(c) {
+		a.done = true
+	}
+	return c.node
+}
+
+// Visits the elements of slice "nodes", found in field "name" of their
+// parent, and outputs the rebuilt slice. "parent" outputs the parent
+// with the field set to a given slice. If "fixed" is true, elements may
+// be replaced but not deleted or inserted.
+func (a *applier) list(name string, nodes []Node, fixed bool, parent func([]Node) Node) []Node {
+	out := make([]Node, 0, len(nodes))
+	for i, n := range nodes {
+		if a.done {
+			out = append(out, nodes[i:]...)
+			break
+		}
+		iter := &iterator{index: len(out), fixed: fixed}
+		n = a.apply(parent(append(out[:len(out):len(out)], nodes[i:]...)), name, iter, n)
+		out = append(out, iter.before...)
+		if !iter.deleted {
+			out = append(out, n)
+		}
+		out = append(out, iter.after...)
+	}
+	return out
+}
+
+// Visits parameters "params" as "list" visits a slice. Panics if any
+// parameter is replaced or joined by a non-Symbol.
+func (a *applier) params(params []Symbol, parent func([]Symbol) Node) []Symbol {
+	nodes := make([]Node, len(params))
+	for i, p := range params {
+		nodes[i] = p
+	}
+	nodes = a.list("Params", nodes, false, func(nodes []Node) Node {
+		return parent(toSymbols(nodes))
+	})
+	return toSymbols(nodes)
+}
+
+func toSymbols(nodes []Node) []Symbol {
+	params := make([]Symbol, len(nodes))
+	for i, n := range nodes {
+		s, ok := n.(Symbol)
+		if !ok {
+			panic("Params replaced by non-Symbol")
+		}
+		params[i] = s
+	}
+	return params
+}
+
+// Outputs a copy of "n" whose children have been visited.
+func (a *applier) children(n Node) Node {
+	switch n := n.(type) {
+	case Unary:
+		n.X = a.apply(n, "X", nil, n.X)
+		return n
+	case Postfix:
+		n.X = a.apply(n, "X", nil, n.X)
+		return n
+	case Binary:
+		n.X = a.apply(n, "X", nil, n.X)
+		n.Y = a.apply(n, "Y", nil, n.Y)
+		return n
+	case ImpliedBinary:
+		n.X = a.apply(n, "X", nil, n.X)
+		n.Y = a.apply(n, "Y", nil, n.Y)
+		return n
+	case Comparison:
+		n.Operands = a.list("Operands", n.Operands, true, func(operands []Node) Node {
+			parent := n
+			parent.Operands = operands
+			return parent
+		})
+		return n
+	case Conditional:
+		n.Cond = a.apply(n, "Cond", nil, n.Cond)
+		n.Then = a.apply(n, "Then", nil, n.Then)
+		n.Else = a.apply(n, "Else", nil, n.Else)
+		return n
+	case Assign:
+		name, ok := a.apply(n, "Name", nil, n.Name).(Symbol)
+		if !ok {
+			panic("Assign.Name replaced by non-Symbol")
+		}
+		n.Name = name
+		n.Value = a.apply(n, "Value", nil, n.Value)
+		return n
+	case Program:
+		n.Stmts = a.list("Stmts", n.Stmts, false, func(stmts []Node) Node {
+			parent := n
+			parent.Stmts = stmts
+			return parent
+		})
+		return n
+	case Lambda:
+		n.Params = a.params(n.Params, func(params []Symbol) Node {
+			parent := n
+			parent.Params = params
+			return parent
+		})
+		n.Body = a.apply(n, "Body", nil, n.Body)
+		return n
+	case FuncDef:
+		name, ok := a.apply(n, "Name", nil, n.Name).(Symbol)
+		if !ok {
+			panic("FuncDef.Name replaced by non-Symbol")
+		}
+		n.Name = name
+		n.Params = a.params(n.Params, func(params []Symbol) Node {
+			parent := n
+			parent.Params = params
+			return parent
+		})
+		n.Body = a.apply(n, "Body", nil, n.Body)
+		return n
+	case Call:
+		n.Callee = a.apply(n, "Callee", nil, n.Callee)
+		n.Args = a.list("Args", n.Args, false, func(args []Node) Node {
+			parent := n
+			parent.Args = args
+			return parent
+		})
+		return n
+	case List:
+		n.Elems = a.list("Elems", n.Elems, false, func(elems []Node) Node {
+			parent := n
+			parent.Elems = elems
+			return parent
+		})
+		return n
+	case Matrix:
+		rows := make([][]Node, len(n.Rows))
+		copy(rows, n.Rows)
+		for i := range rows {
+			rows[i] = a.list("Rows", rows[i], false, func(row []Node) Node {
+				parent := n
+				parent.Rows = append(append(append([][]Node(nil), rows[:i]...), row), rows[i+1:]...)
+				return parent
+			})
+		}
+		n.Rows = rows
+		return n
+	case Index:
+		n.X = a.apply(n, "X", nil, n.X)
+		n.Indices = a.list("Indices", n.Indices, false, func(indices []Node) Node {
+			parent := n
+			parent.Indices = indices
+			return parent
+		})
+		return n
+	case Paren:
+		n.X = a.apply(n, "X", nil, n.X)
+		return n
+	default:
+		return n
+	}
+}
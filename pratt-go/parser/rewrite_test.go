@@ -0,0 +1,236 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestApplySubstitute(t *testing.T) {
+	node, _ := Parse("x^2 + f(x, y)")
+	result := Apply(node, func(c *Cursor) bool {
+		if s, ok := c.Node().(Symbol); ok && s.Value == "x" {
+			c.Replace(Number{Value: 3, Raw: "3"})
+		}
+		return true
+	}, nil)
+	expect := "3 ^ 2 + f(3, y)"
+	if got := Print(result); got != expect {
+		t.Errorf("TestApplySubstitute failed. Expected: %s, Got: %s", expect, got)
+	}
+	// The original tree is untouched.
+	expect = "x ^ 2 + f(x, y)"
+	if got := Print(node); got != expect {
+		t.Errorf("TestApplySubstitute failed. Expected: %s, Got: %s", expect, got)
+	}
+}
+
+func TestApplyFold(t *testing.T) {
+	node, _ := Parse("2 * 3 + x * (4 - 1)")
+	result := Apply(node, nil, func(c *Cursor) bool {
+		b, ok := c.Node().(Binary)
+		if !ok {
+			return true
+		}
+		x, ok := b.X.(Number)
+		if !ok {
+			return true
+		}
+		y, ok := b.Y.(Number)
+		if !ok {
+			return true
+		}
+		var v float64
+		switch b.Op {
+		case "+":
+			v = x.Value + y.Value
+		case "-":
+			v = x.Value - y.Value
+		case "*":
+			v = x.Value * y.Value
+		default:
+			return true
+		}
+		c.Replace(Number{Value: v, Line: b.Line, Column: b.Column})
+		return true
+	})
+	expect := "6 + x * 3"
+	if got := (PrintConfig{Unparen: true}).Print(result); got != expect {
+		t.Errorf("TestApplyFold failed. Expected: %s, Got: %s", expect, got)
+	}
+}
+
+func TestApplyCursor(t *testing.T) {
+	node, _ := Parse("-f(a, b + c)")
+	var trace []string
+	Apply(node, func(c *Cursor) bool {
+		parent := "nil"
+		if c.Parent() != nil {
+			parent = describe(c.Parent())
+		}
+		trace = append(trace, describe(c.Node())+" in "+parent+"."+c.Name()+" at "+strconv.Itoa(c.Index()))
+		return true
+	}, nil)
+	expect := []string{
+		"unary - in nil. at -1",
+		"call in unary -.X at -1",
+		"f in call.Callee at -1",
+		"a in call.Args at 0",
+		"binary + in call.Args at 1",
+		"b in binary +.X at -1",
+		"c in binary +.Y at -1",
+	}
+	if got, want := strings.Join(trace, "; "), strings.Join(expect, "; "); got != want {
+		t.Errorf("TestApplyCursor failed. Expected: %s, Got: %s", want, got)
+	}
+}
+
+func TestApplyArgs(t *testing.T) {
+	node, _ := Parse("f(a, b, c, d)")
+	result := Apply(node, func(c *Cursor) bool {
+		s, ok := c.Node().(Symbol)
+		if !ok || c.Name() != "Args" {
+			return true
+		}
+		switch s.Value {
+		case "a":
+			c.InsertBefore(Symbol{Value: "z"})
+		case "b":
+			c.Delete()
+		case "c":
+			c.InsertAfter(Symbol{Value: "y"})
+			c.InsertAfter(Symbol{Value: "x"})
+		case "d":
+			if c.Index() != 5 {
+				t.Errorf("TestApplyArgs failed. Expected: index 5, Got: %d", c.Index())
+			}
+			if call := c.Parent().(Call); Print(call) != "f(z, a, c, x, y, d)" {
+				t.Errorf("TestApplyArgs failed. Expected: f(z, a, c, x, y, d), Got: %s", Print(call))
+			}
+		}
+		return true
+	}, nil)
+	expect := "f(z, a, c, x, y, d)"
+	if got := Print(result); got != expect {
+		t.Errorf("TestApplyArgs failed. Expected: %s, Got: %s", expect, got)
+	}
+}
+
+func TestApplyPrune(t *testing.T) {
+	node, _ := Parse("x + f(x) + x")
+	visits := 0
+	result := Apply(node, func(c *Cursor) bool {
+		if _, ok := c.Node().(Call); ok {
+			return false
+		}
+		if s, ok := c.Node().(Symbol); ok && s.Value == "x" {
+			c.Replace(Symbol{Value: "y"})
+		}
+		return true
+	}, func(c *Cursor) bool {
+		visits += 1
+		return true
+	})
+	expect := "y + f(x) + y"
+	if got := Print(result); got != expect {
+		t.Errorf("TestApplyPrune failed. Expected: %s, Got: %s", expect, got)
+	}
+	// Post skips the call and its children.
+	if visits != 4 {
+		t.Errorf("TestApplyPrune failed. Expected: 4 visits, Got: %d", visits)
+	}
+}
+
+func TestApplyTerminate(t *testing.T) {
+	node, _ := Parse("f(a, b, c)")
+	result := Apply(node, nil, func(c *Cursor) bool {
+		s, ok := c.Node().(Symbol)
+		if !ok {
+			return true
+		}
+		c.Replace(Symbol{Value: strings.ToUpper(s.Value)})
+		return s.Value != "a"
+	})
+	expect := "F(A, b, c)"
+	if got := Print(result); got != expect {
+		t.Errorf("TestApplyTerminate failed. Expected: %s, Got: %s", expect, got)
+	}
+}
+
+func TestApplyRoot(t *testing.T) {
+	node, _ := Parse("x")
+	result := Apply(node, func(c *Cursor) bool {
+		switch n := c.Node().(type) {
+		case Symbol:
+			if n.Value == "x" {
+				c.Replace(Unary{Op: "-", X: Symbol{Value: "y"}})
+			} else {
+				c.Replace(Symbol{Value: "z"})
+			}
+		}
+		return true
+	}, nil)
+	// The replacement's children are traversed.
+	expect := "-z"
+	if got := Print(result); got != expect {
+		t.Errorf("TestApplyRoot failed. Expected: %s, Got: %s", expect, got)
+	}
+}
+
+func TestApplyDeletePanics(t *testing.T) {
+	node, _ := Parse("x + y")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("TestApplyDeletePanics failed. Expected: panic")
+		}
+	}()
+	Apply(node, func(c *Cursor) bool {
+		if c.Name() == "Y" {
+			c.Delete()
+		}
+		return true
+	}, nil)
+}
+
+func TestApplyDeleteOperandPanics(t *testing.T) {
+	node, _ := Parse("a < b < c")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("TestApplyDeleteOperandPanics failed. Expected: panic")
+		}
+	}()
+	Apply(node, func(c *Cursor) bool {
+		if c.Name() == "Operands" && c.Index() == 1 {
+			c.Delete()
+		}
+		return true
+	}, nil)
+}
+
+func TestApplyParams(t *testing.T) {
+	node, _ := Parse("(x, y) -> x + y")
+	result := Apply(node, func(c *Cursor) bool {
+		if c.Name() == "Params" && c.Index() == 1 {
+			c.Delete()
+		}
+		if s, ok := c.Node().(Symbol); ok && s.Value == "y" {
+			c.Replace(Number{Value: 1, Raw: "1"})
+		}
+		return true
+	}, nil)
+	expect := "(x) -> x + 1"
+	if got := Print(result); got != expect {
+		t.Errorf("TestApplyParams failed. Expected: %s, Got: %s", expect, got)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("TestApplyParams failed. Expected: panic")
+		}
+	}()
+	Apply(node, func(c *Cursor) bool {
+		if c.Name() == "Params" {
+			c.Replace(Number{Value: 1, Raw: "1"})
+		}
+		return true
+	}, nil)
+}
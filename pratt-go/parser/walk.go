@@ -0,0 +1,111 @@
+package parser
+
+import "fmt"
+
+// A Visitor's "Visit" method is invoked for each node encountered by
+// "Walk". If the result visitor "w" is not nil, "Walk" visits each of
+// the children of the node with "w", followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Traverses an AST in depth-first order: starts by calling v.Visit(node);
+// "node" must not be nil. If the visitor "w" returned by v.Visit(node) is
+// not nil, "Walk" is invoked recursively with visitor "w" for each of the
+// non-nil children of "node", followed by a call of w.Visit(nil).
+// Children are visited in source order: a call visits its callee,
+// then its arguments.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+	switch n := node.(type) {
+	case Empty, Bad, Number, String, Symbol, Boolean:
+		// nothing to do
+	case Unary:
+		walk(v, n.X)
+	case Postfix:
+		walk(v, n.X)
+	case Binary:
+		walk(v, n.X)
+		walk(v, n.Y)
+	case ImpliedBinary:
+		walk(v, n.X)
+		walk(v, n.Y)
+	case Comparison:
+		for _, x := range n.Operands {
+			walk(v, x)
+		}
+	case Conditional:
+		walk(v, n.Cond)
+		walk(v, n.Then)
+		walk(v, n.Else)
+	case Assign:
+		walk(v, n.Name)
+		walk(v, n.Value)
+	case Program:
+		for _, stmt := range n.Stmts {
+			walk(v, stmt)
+		}
+	case Lambda:
+		for _, p := range n.Params {
+			walk(v, p)
+		}
+		walk(v, n.Body)
+	case FuncDef:
+		walk(v, n.Name)
+		for _, p := range n.Params {
+			walk(v, p)
+		}
+		walk(v, n.Body)
+	case Call:
+		walk(v, n.Callee)
+		for _, arg := range n.Args {
+			walk(v, arg)
+		}
+	case List:
+		for _, x := range n.Elems {
+			walk(v, x)
+		}
+	case Matrix:
+		for _, row := range n.Rows {
+			for _, x := range row {
+				walk(v, x)
+			}
+		}
+	case Index:
+		walk(v, n.X)
+		for _, x := range n.Indices {
+			walk(v, x)
+		}
+	case Paren:
+		walk(v, n.X)
+	default:
+		panic(fmt.Sprintf("parser.Walk: unexpected node type %T", n))
+	}
+	v.Visit(nil)
+}
+
+// Walks "node" unless it is nil.
+func walk(v Visitor, node Node) {
+	if node != nil {
+		Walk(v, node)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Traverses an AST in depth-first order: starts by calling f(node);
+// "node" must not be nil. If "f" returns true, "Inspect" invokes "f"
+// recursively for each of the non-nil children of "node", followed by
+// a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
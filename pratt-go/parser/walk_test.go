@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Outputs a short description of a node for tracing traversals.
+func describe(n Node) string {
+	switch n := n.(type) {
+	case nil:
+		return "end"
+	case Number:
+		return n.Raw
+	case Symbol:
+		return n.Value
+	case Unary:
+		return "unary " + n.Op
+	case Binary:
+		return "binary " + n.Op
+	case ImpliedBinary:
+		return "implied " + n.Op
+	case Call:
+		return "call"
+	default:
+		return fmt.Sprintf("%T", n)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	node, _ := Parse("sum(7, 11x) + -y")
+	var trace []string
+	Inspect(node, func(n Node) bool {
+		trace = append(trace, describe(n))
+		return true
+	})
+	expect := "binary +, call, sum, end, 7, end, implied *, 11, end, x, end, end, end, " +
+		"unary -, y, end, end, end"
+	result := strings.Join(trace, ", ")
+	if result != expect {
+		t.Errorf("TestInspect failed. Expected: %s, Got: %s", expect, result)
+	}
+}
+
+func TestInspectPrune(t *testing.T) {
+	node, _ := Parse("f(x, y) + g(z) * w")
+	var symbols []string
+	Inspect(node, func(n Node) bool {
+		switch n := n.(type) {
+		case Call:
+			// Skips the callee and arguments.
+			return false
+		case Symbol:
+			symbols = append(symbols, n.Value)
+		}
+		return true
+	})
+	expect := "w"
+	result := strings.Join(symbols, ", ")
+	if result != expect {
+		t.Errorf("TestInspectPrune failed. Expected: %s, Got: %s", expect, result)
+	}
+}
+
+// Records the depth of each symbol.
+type depths struct {
+	depth  int
+	result *[]string
+}
+
+func (d depths) Visit(n Node) Visitor {
+	if n == nil {
+		return nil
+	}
+	if s, ok := n.(Symbol); ok {
+		*d.result = append(*d.result, fmt.Sprintf("%s:%d", s.Value, d.depth))
+	}
+	return depths{depth: d.depth + 1, result: d.result}
+}
+
+func TestWalk(t *testing.T) {
+	node, _ := Parse("a + b * (c ^ d)")
+	var result []string
+	Walk(depths{result: &result}, node)
+	// Parentheses add a level.
+	expect := "a:1, b:2, c:4, d:4"
+	if got := strings.Join(result, ", "); got != expect {
+		t.Errorf("TestWalk failed. Expected: %s, Got: %s", expect, got)
+	}
+}
+
+func TestWalkAll(t *testing.T) {
+	node, errs := ParseAll("1 + (* 2)")
+	if errs == nil {
+		t.Fatalf("TestWalkAll failed. Expected: errors, Got: %s", node)
+	}
+	bad := 0
+	Inspect(node, func(n Node) bool {
+		if _, ok := n.(Bad); ok {
+			bad += 1
+		}
+		return true
+	})
+	if bad != 1 {
+		t.Errorf("TestWalkAll failed. Expected: 1 Bad node, Got: %d", bad)
+	}
+}